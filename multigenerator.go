@@ -2,9 +2,12 @@ package multigenerator
 
 import (
 	"github.com/ahmadrezamusthafa/multigenerator/querygen"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/registry"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/utils"
 	"github.com/ahmadrezamusthafa/multigenerator/structgen"
 	"github.com/ahmadrezamusthafa/multigenerator/validator"
+	"time"
 )
 
 /*
@@ -23,6 +26,102 @@ func GenerateCondition(astQuery string) (types.Condition, error) {
 	return gen.GenerateCondition(astQuery)
 }
 
+/*
+GenerateConditionWithLimits
+-----------------------------------------------------------------------
+is a function to generate condition object like GenerateCondition, for
+queries from untrusted input, returning a *types.LimitError when the
+query exceeds one of the limits
+
+Param:
+@astQuery is abstract syntax tree query
+@limits is the limits, zero fields take their value from types.DefaultLimits
+*/
+func GenerateConditionWithLimits(astQuery string, limits types.Limits) (types.Condition, error) {
+	gen := structgen.StructGen{Limits: limits}
+	return gen.GenerateCondition(astQuery)
+}
+
+/*
+GenerateConditionWithDialect
+-----------------------------------------------------------------------
+is a function to generate condition object like GenerateCondition from
+a query written in another syntax, like == or : for equality, giving
+the same condition object
+
+Param:
+@astQuery is abstract syntax tree query
+@dialect is the syntax, usually derived from structgen.DefaultDialect
+*/
+func GenerateConditionWithDialect(astQuery string, dialect structgen.Dialect) (types.Condition, error) {
+	gen, err := structgen.New(dialect)
+	if err != nil {
+		return types.Condition{}, err
+	}
+	return gen.GenerateCondition(astQuery)
+}
+
+/*
+CheckLimits
+-----------------------------------------------------------------------
+is a function to check a condition object from untrusted input, like one
+decoded from JSON, before it's used by
+ - Validate
+ - ValidateObjects
+ - ValidateCondition
+ - GenerateQuery
+
+Param:
+@condition is a condition object
+@limits is the limits, zero fields take their value from types.DefaultLimits
+*/
+func CheckLimits(condition types.Condition, limits types.Limits) error {
+	return limits.CheckCondition(&condition)
+}
+
+/*
+GenerateRules
+-----------------------------------------------------------------------
+is a function to generate the condition objects of a rule file, where
+every rule starts at a line beginning with its name and a colon, like
+ vip: spend > 1000 && tier = gold
+
+Param:
+@rules is the rule file content
+*/
+func GenerateRules(rules string) (map[string]types.Condition, error) {
+	var gen structgen.StructGen
+	return gen.GenerateRules(rules)
+}
+
+/*
+Format
+-----------------------------------------------------------------------
+is a function to format a condition object back to the query syntax,
+which GenerateCondition parses to the same condition object
+
+Param:
+@condition is a condition object
+*/
+func Format(condition types.Condition) string {
+	return structgen.Format(condition)
+}
+
+/*
+Bind
+-----------------------------------------------------------------------
+is a function to bind the parameters of a condition, like :member_id,
+to their values
+
+Param:
+@condition is a condition object with parameters
+@params is the parameter values by name
+*/
+func Bind(condition types.Condition, params map[string]interface{}) (types.Condition, error) {
+	var gen structgen.StructGen
+	return gen.BindCondition(condition, params)
+}
+
 func Validate(referenceCondition types.Condition, data interface{}) (isValid bool, err error) {
 	con := validator.Condition{Condition: &referenceCondition}
 	return con.Validate(data)
@@ -56,3 +155,90 @@ func GenerateQuery(mainQuery string, baseCondition types.BaseCondition) (string,
 	var gen querygen.QueryGen
 	return gen.GenerateQuery(mainQuery, baseCondition)
 }
+
+/*
+GenerateQueryWithArgs
+-----------------------------------------------------------------------
+is a function to generate SQL query with placeholders for the condition
+parameters and their values as driver arguments
+
+Param:
+@mainQuery is a parent query
+@baseCondition is a condition object with header and footer info
+@params is the parameter values by name
+*/
+func GenerateQueryWithArgs(mainQuery string, baseCondition types.BaseCondition, params map[string]interface{}) (string, []interface{}, error) {
+	var gen querygen.QueryGen
+	return gen.GenerateQueryWithArgs(mainQuery, baseCondition, params)
+}
+
+/*
+RegisterFunction
+-----------------------------------------------------------------------
+is a function to register a custom function, like is_weekend(order_date),
+used by
+ - GenerateCondition
+ - Validate
+ - GenerateQuery, when the function has a SQL template
+
+Param:
+@function is the function name, argument types, return type and implementation
+*/
+func RegisterFunction(function registry.Function) error {
+	return registry.Default.RegisterFunction(function)
+}
+
+/*
+RegisterOperator
+-----------------------------------------------------------------------
+is a function to register a custom binary operator, like tags HAS "vip",
+used by
+ - GenerateCondition
+ - Validate
+ - GenerateQuery, when the operator has a SQL template
+
+Param:
+@operator is the operator name, operand types and implementation
+*/
+func RegisterOperator(operator registry.Operator) error {
+	return registry.Default.RegisterOperator(operator)
+}
+
+/*
+RegisterCondition
+-----------------------------------------------------------------------
+is a function to register a named condition, like tech_divisions for
+division=engineering || division=finance, referenced as @tech_divisions
+and expanded by
+ - GenerateCondition
+ - GenerateRules
+
+Param:
+@name is the condition name
+@query is the condition, parsed when it's referenced
+*/
+func RegisterCondition(name, query string) error {
+	return registry.Default.RegisterCondition(name, query)
+}
+
+/*
+SetDefaultLocation
+-----------------------------------------------------------------------
+is a function to set the location of dates and times without a time zone,
+like 2019-09-09 10:00:00, used by
+ - Validate
+ - ValidateObjects
+ - ValidateCondition
+ - Bind, when writing bound times
+ - GenerateQuery, when rendering resolved timestamps
+
+The location is shared by the whole process, it's only a fallback for the
+Location of validator.Condition, structgen.StructGen and querygen.QueryGen,
+which should be set instead when callers need different locations.
+
+Param:
+@location is the default location, nil resets it to UTC
+*/
+func SetDefaultLocation(location *time.Location) {
+	utils.SetDefaultLocation(location)
+}
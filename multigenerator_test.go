@@ -2,8 +2,14 @@ package multigenerator
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/registry"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"github.com/ahmadrezamusthafa/multigenerator/structgen"
+	"github.com/ahmadrezamusthafa/multigenerator/validator"
 	"reflect"
 	"regexp"
 	"strings"
@@ -33,7 +39,7 @@ func TestGenerateCondition(t *testing.T) {
                       )
 `,
 			},
-			want:    `{"conditions":[{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2"}}]},{"operator":"OR","conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance"}}]}]}`,
+			want:    `{"conditions":[{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2","type":"numeric"}}]},{"operator":"OR","conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance","type":"alphanumeric"}}]}]}`,
 			wantErr: false,
 		},
 		{
@@ -48,7 +54,7 @@ func TestGenerateCondition(t *testing.T) {
                       ) && user_id = 43
 `,
 			},
-			want:    `{"conditions":[{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2"}}]},{"operator":"OR","conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance"}}]},{"operator":"AND","attribute":{"name":"user_id","operator":"=","value":"43"}}]}`,
+			want:    `{"conditions":[{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2","type":"numeric"}}]},{"operator":"OR","conditions":[{"conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance","type":"alphanumeric"}}]},{"operator":"AND","attribute":{"name":"user_id","operator":"=","value":"43","type":"numeric"}}]}]}`,
 			wantErr: false,
 		},
 		{
@@ -63,7 +69,7 @@ func TestGenerateCondition(t *testing.T) {
                       )
 `,
 			},
-			want:    `{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2"}},{"operator":"AND","conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance"}}]}]}`,
+			want:    `{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2","type":"numeric"}},{"operator":"AND","conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance","type":"alphanumeric"}}]}]}`,
 			wantErr: false,
 		},
 		{
@@ -71,7 +77,7 @@ func TestGenerateCondition(t *testing.T) {
 			args: args{
 				query: `id=1 &&  member_id=2   &&   (division=engineering || division=finance)`,
 			},
-			want:    `{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2"}},{"operator":"AND","conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance"}}]}]}`,
+			want:    `{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2","type":"numeric"}},{"operator":"AND","conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance","type":"alphanumeric"}}]}]}`,
 			wantErr: false,
 		},
 		{
@@ -91,7 +97,7 @@ func TestGenerateCondition(t *testing.T) {
                   )
 `,
 			},
-			want:    `{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2"}},{"operator":"AND","attribute":{"name":"user_id","operator":"=","value":"3"}},{"operator":"AND","conditions":[{"attribute":{"name":"province","operator":"=","value":"jatim"}},{"operator":"OR","attribute":{"name":"city","operator":"=","value":"mojokerto"}},{"operator":"OR","conditions":[{"attribute":{"name":"warehouse_id","operator":"=","value":"1"}},{"operator":"AND","attribute":{"name":"warehouse_detail_id","operator":"=","value":"2"}}]}]}]}`,
+			want:    `{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2","type":"numeric"}},{"operator":"AND","attribute":{"name":"user_id","operator":"=","value":"3","type":"numeric"}},{"operator":"AND","conditions":[{"attribute":{"name":"province","operator":"=","value":"jatim","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"city","operator":"=","value":"mojokerto","type":"alphanumeric"}},{"operator":"OR","conditions":[{"attribute":{"name":"warehouse_id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"warehouse_detail_id","operator":"=","value":"2","type":"numeric"}}]}]}]}`,
 			wantErr: false,
 		},
 		{
@@ -112,7 +118,7 @@ func TestGenerateCondition(t *testing.T) {
 				  && data_id = 54
 `,
 			},
-			want:    `{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2"}},{"operator":"AND","attribute":{"name":"user_id","operator":"=","value":"3"}},{"operator":"AND","conditions":[{"attribute":{"name":"province","operator":"=","value":"jatim"}},{"operator":"OR","attribute":{"name":"city","operator":"=","value":"mojokerto"}},{"operator":"OR","conditions":[{"attribute":{"name":"warehouse_id","operator":"=","value":"1"}},{"operator":"AND","attribute":{"name":"warehouse_detail_id","operator":"=","value":"2"}}]}]},{"operator":"AND","attribute":{"name":"data_id","operator":"=","value":"54"}}]}`,
+			want:    `{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2","type":"numeric"}},{"operator":"AND","attribute":{"name":"user_id","operator":"=","value":"3","type":"numeric"}},{"operator":"AND","conditions":[{"attribute":{"name":"province","operator":"=","value":"jatim","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"city","operator":"=","value":"mojokerto","type":"alphanumeric"}},{"operator":"OR","conditions":[{"attribute":{"name":"warehouse_id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"warehouse_detail_id","operator":"=","value":"2","type":"numeric"}}]}]},{"operator":"AND","attribute":{"name":"data_id","operator":"=","value":"54","type":"numeric"}}]}`,
 			wantErr: false,
 		},
 		{
@@ -120,7 +126,7 @@ func TestGenerateCondition(t *testing.T) {
 			args: args{
 				query: "((date<=2019-09-09 && date > 2019-08-08) || (p_date>=2019-01-01 && p_date<2019-02-02)) && (member_type=1||member_type=2)",
 			},
-			want:    `{"conditions":[{"conditions":[{"conditions":[{"attribute":{"name":"date","operator":"\u003c=","value":"2019-09-09"}},{"operator":"AND","attribute":{"name":"date","operator":"\u003e","value":"2019-08-08"}}]},{"operator":"OR","conditions":[{"attribute":{"name":"p_date","operator":"\u003e=","value":"2019-01-01"}},{"operator":"AND","attribute":{"name":"p_date","operator":"\u003c","value":"2019-02-02"}}]}]},{"operator":"AND","conditions":[{"attribute":{"name":"member_type","operator":"=","value":"1"}},{"operator":"OR","attribute":{"name":"member_type","operator":"=","value":"2"}}]}]}`,
+			want:    `{"conditions":[{"conditions":[{"conditions":[{"attribute":{"name":"date","operator":"\u003c=","value":"2019-09-09","type":"date"}},{"operator":"AND","attribute":{"name":"date","operator":"\u003e","value":"2019-08-08","type":"date"}}]},{"operator":"OR","conditions":[{"attribute":{"name":"p_date","operator":"\u003e=","value":"2019-01-01","type":"date"}},{"operator":"AND","attribute":{"name":"p_date","operator":"\u003c","value":"2019-02-02","type":"date"}}]}]},{"operator":"AND","conditions":[{"attribute":{"name":"member_type","operator":"=","value":"1","type":"numeric"}},{"operator":"OR","attribute":{"name":"member_type","operator":"=","value":"2","type":"numeric"}}]}]}`,
 			wantErr: false,
 		},
 	}
@@ -184,6 +190,38 @@ func TestCondition_Validate(t *testing.T) {
 			wantIsValid: true,
 			wantErr:     false,
 		},
+		{
+			name: "Normal case - struct validation - hyphenated attribute names",
+			args: args{
+				query: `x-y = 1 && user-agent = "x"`,
+				object: struct {
+					XY        int    `json:"x-y"`
+					UserAgent string `json:"user-agent"`
+				}{
+					XY:        1,
+					UserAgent: "x",
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - subtraction without spaces",
+			args: args{
+				query: `stock-reserved<=5 && stock-reserved*2 > 7 && x-1 = 3`,
+				object: struct {
+					Stock    int `json:"stock"`
+					Reserved int `json:"reserved"`
+					X        int `json:"x"`
+				}{
+					Stock:    8,
+					Reserved: 4,
+					X:        4,
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
 		{
 			name: "Normal case - struct validation - brand attribute is not exist",
 			args: args{
@@ -202,93 +240,1226 @@ func TestCondition_Validate(t *testing.T) {
 			wantErr:     false,
 		},
 		{
-			name: "Normal case - struct validation",
-			args: args{
-				query: `id=1 &&  member_id=3  && ((division=engineering || division=finance || division=people)&&(member_id=2||id=1))`,
-				object: struct {
-					ID       int    `json:"id"`
-					MemberID int    `json:"member_id"`
-					Division string `json:"division"`
-				}{
-					ID:       1,
-					MemberID: 3,
-					Division: "people",
-				},
-			},
+			name: "Normal case - struct validation",
+			args: args{
+				query: `id=1 &&  member_id=3  && ((division=engineering || division=finance || division=people)&&(member_id=2||id=1))`,
+				object: struct {
+					ID       int    `json:"id"`
+					MemberID int    `json:"member_id"`
+					Division string `json:"division"`
+				}{
+					ID:       1,
+					MemberID: 3,
+					Division: "people",
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation",
+			args: args{
+				query: `ID=1 &&  MemberID=2  &&   (Division=engineering || Division=finance)`,
+				object: struct {
+					ID       int
+					MemberID int
+					Division string
+				}{
+					ID:       1,
+					MemberID: 2,
+					Division: "engineering",
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - Brand attribute is not exist",
+			args: args{
+				query: `ID=1 &&  MemberID=2  &&   (Division=engineering || Division=finance) && Brand=Adidas`,
+				object: struct {
+					ID       int
+					MemberID int
+					Division string
+				}{
+					ID:       1,
+					MemberID: 2,
+					Division: "engineering",
+				},
+			},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - skip not exist attribute because using OR condition",
+			args: args{
+				query: `ID=1 &&  MemberID=2  &&   (Division=engineering || Division=finance) && (Category=Bawahan || ID=1 || Brand=nike)`,
+				object: struct {
+					ID       int
+					MemberID int
+					Division string
+				}{
+					ID:       1,
+					MemberID: 2,
+					Division: "engineering",
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - not equal, include and exclude operator",
+			args: args{
+				query: `id != 2 && member_id IN (1,2,3) && division NOT IN (people, finance)`,
+				object: struct {
+					ID       int    `json:"id"`
+					MemberID int    `json:"member_id"`
+					Division string `json:"division"`
+				}{
+					ID:       1,
+					MemberID: 3,
+					Division: "engineering",
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - exclude operator",
+			args: args{
+				query: `id IN (1,2) && division NOT IN (people, finance)`,
+				object: struct {
+					ID       int    `json:"id"`
+					Division string `json:"division"`
+				}{
+					ID:       1,
+					Division: "finance",
+				},
+			},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - map validation - include operator",
+			args: args{
+				query: `member_id IN (1,2,3) && division != people`,
+				object: map[string]interface{}{
+					"member_id": 2,
+					"division":  "finance",
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - map validation - exclude operator",
+			args: args{
+				query: `member_id NOT IN (1,2,3)`,
+				object: map[string]interface{}{
+					"member_id": 2,
+				},
+			},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - null operator",
+			args: args{
+				query: `deleted_at IS NULL && coupon IS NOT NULL && note IS NULL`,
+				object: struct {
+					DeletedAt *time.Time  `json:"deleted_at"`
+					Coupon    *string     `json:"coupon"`
+					Note      interface{} `json:"note"`
+				}{
+					Coupon: new(string),
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - nil pointer is not comparable",
+			args: args{
+				query: `score != 10`,
+				object: struct {
+					Score *int `json:"score"`
+				}{},
+			},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - map validation - null operator",
+			args: args{
+				query: `deleted_at IS NULL && coupon IS NULL && member_id IS NOT NULL`,
+				object: map[string]interface{}{
+					"member_id": 2,
+					"coupon":    nil,
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - map validation - not null operator on missing key",
+			args: args{
+				query: `member_id IS NOT NULL`,
+				object: map[string]interface{}{
+					"id": 2,
+				},
+			},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - string pattern operator",
+			args: args{
+				query: `name LIKE "PRM-_0%" && code STARTS WITH 12 && city ENDS WITH karta && note CONTAINS "50%"`,
+				object: struct {
+					Name string  `json:"name"`
+					Code int     `json:"code"`
+					City *string `json:"city"`
+					Note string  `json:"note"`
+				}{
+					Name: "PRM-101",
+					Code: 1234,
+					City: func(s string) *string { return &s }("Jakarta"),
+					Note: "discount 50% off",
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - escaped like pattern",
+			args: args{
+				query: `name LIKE "100\%" || name LIKE "a\_c"`,
+				object: struct {
+					Name string `json:"name"`
+				}{
+					Name: "abc",
+				},
+			},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - map validation - like operator is case sensitive",
+			args: args{
+				query: `name LIKE "prm%"`,
+				object: map[string]interface{}{
+					"name": "PRM-101",
+				},
+			},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - regex operator",
+			args: args{
+				query: `name ~ "^PRM-[0-9]+$" && code ~ ^12`,
+				object: struct {
+					Name string `json:"name"`
+					Code int    `json:"code"`
+				}{
+					Name: "PRM-101",
+					Code: 1234,
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - map validation - regex operator",
+			args: args{
+				query: `name ~ "^PRM-[0-9]+$"`,
+				object: map[string]interface{}{
+					"name": "PRM-10a",
+				},
+			},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - negated group",
+			args: args{
+				query: `!(division=engineering || division=finance) && !id=2`,
+				object: struct {
+					ID       int    `json:"id"`
+					Division string `json:"division"`
+				}{
+					ID:       1,
+					Division: "people",
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - map validation - negated group",
+			args: args{
+				query: `id=1 && !(division=engineering || division=finance)`,
+				object: map[string]interface{}{
+					"id":       1,
+					"division": "finance",
+				},
+			},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - quoted values",
+			args: args{
+				query: `city = 'New York' && name = O'Brien && product = "Kaos \"Polos\" 2'"`,
+				object: struct {
+					City    string `json:"city"`
+					Name    string `json:"name"`
+					Product string `json:"product"`
+				}{
+					City:    "New York",
+					Name:    "O'Brien",
+					Product: `Kaos "Polos" 2'`,
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - typed literal",
+			args: args{
+				query: `is_active = true && is_deleted != true && deleted_at = null && code = "123" && note != null`,
+				object: struct {
+					IsActive  bool       `json:"is_active"`
+					IsDeleted bool       `json:"is_deleted"`
+					DeletedAt *time.Time `json:"deleted_at"`
+					Code      string     `json:"code"`
+					Note      string     `json:"note"`
+				}{
+					IsActive: true,
+					Code:     "123",
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - map validation - typed literal",
+			args: args{
+				query: `is_active = false || deleted_at != null`,
+				object: map[string]interface{}{
+					"is_active": true,
+				},
+			},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - attribute reference",
+			args: args{
+				query: `shipped_at > $ordered_at && used_quota < $max_quota && is_paid = $is_confirmed`,
+				object: struct {
+					OrderedAt   time.Time  `json:"ordered_at"`
+					ShippedAt   *time.Time `json:"shipped_at"`
+					UsedQuota   int        `json:"used_quota"`
+					MaxQuota    int        `json:"max_quota"`
+					IsPaid      bool       `json:"is_paid"`
+					IsConfirmed *bool      `json:"is_confirmed"`
+				}{
+					OrderedAt:   time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC),
+					ShippedAt:   func() *time.Time { t := time.Date(2020, 1, 2, 10, 0, 0, 0, time.UTC); return &t }(),
+					UsedQuota:   4,
+					MaxQuota:    5,
+					IsPaid:      true,
+					IsConfirmed: func() *bool { b := true; return &b }(),
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - map validation - missing attribute reference",
+			args: args{
+				query: `used_quota < $max_quota`,
+				object: map[string]interface{}{
+					"used_quota": 4,
+				},
+			},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name: "Error case - unbound parameter",
+			args: args{
+				query: `member_id = :member_id`,
+				object: struct {
+					MemberID int `json:"member_id"`
+				}{
+					MemberID: 45,
+				},
+			},
+			wantIsValid: false,
+			wantErr:     true,
+		},
+		{
+			name: "Normal case - struct validation - arithmetic expression",
+			args: args{
+				query: `price * quantity > 1000000 && stock - reserved <= 5 && total / quantity = price + 2.5 && quantity % 2 = 1`,
+				object: struct {
+					Price    float64 `json:"price"`
+					Quantity int     `json:"quantity"`
+					Stock    *int    `json:"stock"`
+					Reserved int64   `json:"reserved"`
+					Total    int     `json:"total"`
+				}{
+					Price:    250000.5,
+					Quantity: 5,
+					Stock:    func() *int { i := 10; return &i }(),
+					Reserved: 7,
+					Total:    1250015,
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - map validation - arithmetic expression with missing attribute",
+			args: args{
+				query: `stock - reserved <= 5 || used / 0 > 1`,
+				object: map[string]interface{}{
+					"stock": 10,
+					"used":  4,
+				},
+			},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - function call",
+			args: args{
+				query: `lower(email) = "budi@mail.com" && len(city) > 8 && count(tags) > 2 && year(created_at) = 2020 && coalesce(nickname, name) = "budi" && abs(balance) >= 10 && trim(upper(city)) LIKE "JAK%"`,
+				object: struct {
+					Email     string    `json:"email"`
+					Tags      []string  `json:"tags"`
+					CreatedAt time.Time `json:"created_at"`
+					Nickname  *string   `json:"nickname"`
+					Name      string    `json:"name"`
+					Balance   float64   `json:"balance"`
+					City      string    `json:"city"`
+				}{
+					Email:     "Budi@Mail.com",
+					Tags:      []string{"a", "b", "c"},
+					CreatedAt: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+					Name:      "budi",
+					Balance:   -12.5,
+					City:      " jakarta ",
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - map validation - function call with missing attribute",
+			args: args{
+				query: `len(tags) > 0 || coalesce(nickname, name) IS NULL`,
+				object: map[string]interface{}{
+					"id": 1,
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - range",
+			args: args{
+				query: `price BETWEEN 100 AND 500 && created_at NOT BETWEEN "2019-08-08 00:00:00" AND "2019-09-09 00:00:00"`,
+				object: struct {
+					Price     float64   `json:"price"`
+					CreatedAt time.Time `json:"created_at"`
+				}{
+					Price:     500,
+					CreatedAt: time.Date(2019, 9, 9, 0, 0, 1, 0, time.UTC),
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - range not matched",
+			args: args{
+				query: `price BETWEEN 100 AND 500 || created_at BETWEEN "2019-08-08 00:00:00" AND "2019-09-09 00:00:00"`,
+				object: map[string]interface{}{
+					"price":      99.5,
+					"created_at": time.Date(2019, 8, 7, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name: "Error case - len of a collection",
+			args: args{
+				query: `len(tags) > 2`,
+				object: map[string]interface{}{
+					"tags": []string{"a", "b", "c"},
+				},
+			},
+			wantIsValid: false,
+			wantErr:     true,
+		},
+		{
+			name: "Error case",
+			args: args{
+				query:  `ID=1 &&  MemberID=2  &&   (Division=engineering || Division=finance)`,
+				object: nil,
+			},
+			wantIsValid: false,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, _ := GenerateCondition(tt.args.query)
+			gotIsValid, err := Validate(condition, tt.args.object)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Condition.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotIsValid != tt.wantIsValid {
+				t.Errorf("Condition.Validate() = %v, want %v", gotIsValid, tt.wantIsValid)
+			}
+		})
+	}
+}
+
+func TestCondition_ValidatePrecedence(t *testing.T) {
+	// a = 1 OR b = 2 AND c = 3 stored as a flat list, the way older versions of
+	// GenerateCondition built it.
+	flatCondition := types.Condition{
+		Conditions: []*types.Condition{
+			{
+				Attribute: &types.Attribute{Name: "a", Operator: "=", Value: "1", Type: valuetype.Numeric},
+			},
+			{
+				Operator:  "OR",
+				Attribute: &types.Attribute{Name: "b", Operator: "=", Value: "2", Type: valuetype.Numeric},
+			},
+			{
+				Operator:  "AND",
+				Attribute: &types.Attribute{Name: "c", Operator: "=", Value: "3", Type: valuetype.Numeric},
+			},
+		},
+	}
+	parsedCondition, err := GenerateCondition("a=1 || b=2 && c=3")
+	if err != nil {
+		t.Fatalf("GenerateCondition() error = %v", err)
+	}
+	object := struct {
+		A int `json:"a"`
+		B int `json:"b"`
+		C int `json:"c"`
+	}{
+		A: 1,
+		B: 0,
+		C: 0,
+	}
+	for name, condition := range map[string]types.Condition{"flat": flatCondition, "parsed": parsedCondition} {
+		t.Run(name, func(t *testing.T) {
+			gotIsValid, err := Validate(condition, object)
+			if err != nil || !gotIsValid {
+				t.Errorf("Condition.Validate() = %v, %v, want true", gotIsValid, err)
+			}
+			gotIsValid, err = Validate(condition, map[string]interface{}{"a": 2, "b": 2, "c": 4})
+			if err != nil || gotIsValid {
+				t.Errorf("Condition.Validate() = %v, %v, want false", gotIsValid, err)
+			}
+			inputCondition, _ := GenerateCondition("a=1 && b=3 && c=4")
+			gotIsValid, err = ValidateCondition(condition, inputCondition)
+			if err != nil || !gotIsValid {
+				t.Errorf("Condition.ValidateCondition() = %v, %v, want true", gotIsValid, err)
+			}
+		})
+	}
+}
+
+func TestCondition_ValidateRegistry(t *testing.T) {
+	categories := map[string]string{"SKU-1": "shoes", "SKU-2": "bags"}
+	err := RegisterFunction(registry.Function{
+		Name:       "is_weekend",
+		Arguments:  []valuetype.ValueType{valuetype.Date},
+		ReturnType: valuetype.Boolean,
+		Call: func(arguments ...interface{}) (interface{}, error) {
+			weekday := arguments[0].(time.Time).Weekday()
+			return weekday == time.Saturday || weekday == time.Sunday, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterFunction() error = %v", err)
+	}
+	err = RegisterFunction(registry.Function{
+		Name:       "sku_in_category",
+		Arguments:  []valuetype.ValueType{valuetype.Alphanumeric, valuetype.Alphanumeric},
+		ReturnType: valuetype.Boolean,
+		Call: func(arguments ...interface{}) (interface{}, error) {
+			category, ok := categories[arguments[0].(string)]
+			if !ok {
+				return nil, errors.New("unknown sku")
+			}
+			return category == arguments[1], nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterFunction() error = %v", err)
+	}
+	err = RegisterOperator(registry.Operator{
+		Name:      "HAS",
+		LeftType:  valuetype.Alphanumeric,
+		RightType: valuetype.Alphanumeric,
+		Call: func(left, right interface{}) (bool, error) {
+			for _, tag := range strings.Split(left.(string), ",") {
+				if tag == right {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterOperator() error = %v", err)
+	}
+	err = RegisterFunction(registry.Function{
+		Name: "IS_WEEKEND",
+		Call: func(arguments ...interface{}) (interface{}, error) {
+			return nil, nil
+		},
+	})
+	if err == nil {
+		t.Errorf("RegisterFunction() registered a duplicate function")
+	}
+
+	type object struct {
+		OrderDate time.Time `json:"order_date"`
+		Sku       string    `json:"sku"`
+		Tags      string    `json:"tags"`
+	}
+	saturday := time.Date(2020, 1, 4, 10, 0, 0, 0, time.UTC)
+	monday := time.Date(2020, 1, 6, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		query       string
+		object      object
+		wantIsValid bool
+		wantErr     bool
+	}{
+		{
+			name:        "Normal case - boolean function",
+			query:       `is_weekend(order_date) && sku_in_category(sku, "shoes")`,
+			object:      object{OrderDate: saturday, Sku: "SKU-1"},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name:        "Normal case - negated boolean function",
+			query:       `!is_weekend(order_date)`,
+			object:      object{OrderDate: saturday},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name:        "Normal case - compared boolean function",
+			query:       `is_weekend(order_date) = false`,
+			object:      object{OrderDate: monday},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name:        "Normal case - custom operator",
+			query:       `tags HAS "vip" || tags HAS "staff"`,
+			object:      object{Tags: "new,vip"},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name:        "Normal case - custom operator not matched",
+			query:       `tags HAS "staff"`,
+			object:      object{Tags: "new,vip"},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name:        "Error case - function error",
+			query:       `sku_in_category(sku, "shoes")`,
+			object:      object{Sku: "SKU-3"},
+			wantIsValid: false,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := GenerateCondition(tt.query)
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			gotIsValid, err := Validate(condition, tt.object)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Condition.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotIsValid != tt.wantIsValid {
+				t.Errorf("Condition.Validate() gotIsValid = %v, want %v", gotIsValid, tt.wantIsValid)
+			}
+		})
+	}
+}
+
+func TestCondition_ValidateRelativeDate(t *testing.T) {
+	now := time.Date(2020, 3, 18, 10, 30, 0, 0, time.UTC)
+	type object struct {
+		RegisteredAt time.Time  `json:"registered_at"`
+		DeletedAt    *time.Time `json:"deleted_at"`
+	}
+	tests := []struct {
+		name        string
+		query       string
+		object      object
+		wantIsValid bool
+	}{
+		{
+			name:        "Normal case - registered in the last 7 days",
+			query:       `registered_at >= now() - 7d`,
+			object:      object{RegisteredAt: now.AddDate(0, 0, -7)},
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - registered before the last 7 days",
+			query:       `registered_at >= now() - 7d`,
+			object:      object{RegisteredAt: now.AddDate(0, 0, -7).Add(-time.Second)},
+			wantIsValid: false,
+		},
+		{
+			name:        "Normal case - registered today",
+			query:       `registered_at >= today() && registered_at < today() + 1d`,
+			object:      object{RegisteredAt: time.Date(2020, 3, 18, 0, 0, 0, 0, time.UTC)},
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - registered this month",
+			query:       `registered_at >= startOfMonth()`,
+			object:      object{RegisteredAt: time.Date(2020, 2, 29, 23, 59, 59, 0, time.UTC)},
+			wantIsValid: false,
+		},
+		{
+			name:        "Normal case - nil time",
+			query:       `deleted_at < now() - 1h`,
+			object:      object{},
+			wantIsValid: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := GenerateCondition(tt.query)
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			con := validator.Condition{
+				Condition: &condition,
+				Now: func() time.Time {
+					return now
+				},
+			}
+			gotIsValid, err := con.Validate(tt.object)
+			if err != nil {
+				t.Errorf("Condition.Validate() error = %v", err)
+				return
+			}
+			if gotIsValid != tt.wantIsValid {
+				t.Errorf("Condition.Validate() gotIsValid = %v, want %v", gotIsValid, tt.wantIsValid)
+			}
+		})
+	}
+
+	condition, err := GenerateCondition(`registered_at >= now() - 1h`)
+	if err != nil {
+		t.Fatalf("GenerateCondition() error = %v", err)
+	}
+	gotIsValid, err := Validate(condition, object{RegisteredAt: time.Now().Add(-time.Minute)})
+	if err != nil || !gotIsValid {
+		t.Errorf("Condition.Validate() = %v, %v, want true", gotIsValid, err)
+	}
+}
+
+func TestCondition_ValidateDateFormat(t *testing.T) {
+	jakarta := time.FixedZone("Asia/Jakarta", 7*60*60)
+	type object struct {
+		CreatedAt   time.Time `json:"created_at"`
+		UpdatedAt   string    `json:"updated_at"`
+		EpochSecond int64     `json:"epoch_second"`
+		EpochMilli  int64     `json:"epoch_milli"`
+	}
+	data := object{
+		CreatedAt:   time.Date(2019, 9, 9, 3, 0, 0, 0, time.UTC),
+		UpdatedAt:   "2019-09-09T10:00:00+07:00",
+		EpochSecond: time.Date(2019, 9, 9, 3, 0, 0, 0, time.UTC).Unix(),
+		EpochMilli:  time.Date(2019, 9, 9, 3, 0, 0, 0, time.UTC).UnixMilli(),
+	}
+	tests := []struct {
+		name        string
+		query       string
+		location    *time.Location
+		wantIsValid bool
+	}{
+		{
+			name:        "Normal case - date only",
+			query:       `created_at > 2019-09-09 && created_at < 2019-09-10`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - RFC 3339",
+			query:       `created_at = 2019-09-09T10:00:00+07:00 && updated_at = "2019-09-09 03:00:00"`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - epoch seconds and milliseconds",
+			query:       `epoch_second = "2019-09-09 03:00:00" && epoch_milli BETWEEN 2019-09-09 AND 2019-09-10`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - epoch literal compared with time",
+			query:       `created_at = 1567998000 && created_at < 1568073600000 && created_at IN (1567998000, 1568000000)`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - default location",
+			query:       `created_at = "2019-09-09 10:00:00" && epoch_second >= 2019-09-09 && updated_at < 2019-09-10`,
+			location:    jakarta,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - default location not matched",
+			query:       `created_at = "2019-09-09 03:00:00"`,
+			location:    jakarta,
+			wantIsValid: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := GenerateCondition(tt.query)
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			con := validator.Condition{Condition: &condition, Location: tt.location}
+			gotIsValid, err := con.Validate(data)
+			if err != nil {
+				t.Errorf("Condition.Validate() error = %v", err)
+				return
+			}
+			if gotIsValid != tt.wantIsValid {
+				t.Errorf("Condition.Validate() gotIsValid = %v, want %v", gotIsValid, tt.wantIsValid)
+			}
+		})
+	}
+
+	SetDefaultLocation(jakarta)
+	defer SetDefaultLocation(nil)
+	condition, err := GenerateCondition(`created_at = "2019-09-09 10:00:00"`)
+	if err != nil {
+		t.Fatalf("GenerateCondition() error = %v", err)
+	}
+	if gotIsValid, err := Validate(condition, data); err != nil || !gotIsValid {
+		t.Errorf("Validate() with default location = %v, %v, want true", gotIsValid, err)
+	}
+	con := validator.Condition{Condition: &condition, Location: time.UTC}
+	if gotIsValid, err := con.Validate(data); err != nil || gotIsValid {
+		t.Errorf("Condition.Validate() with location = %v, %v, want false", gotIsValid, err)
+	}
+}
+
+func TestCondition_ValidateQuantifier(t *testing.T) {
+	type item struct {
+		SKU   string  `json:"sku"`
+		Qty   int     `json:"qty"`
+		Price float64 `json:"price"`
+	}
+	type object struct {
+		Items  []item                   `json:"items"`
+		Lines  []map[string]interface{} `json:"lines"`
+		Tags   [2]string                `json:"tags"`
+		Codes  []*int                   `json:"codes"`
+		Empty  []item                   `json:"empty"`
+		Status string                   `json:"status"`
+	}
+	code := 7
+	data := object{
+		Items: []item{
+			{SKU: "A1", Qty: 3, Price: 10},
+			{SKU: "B2", Qty: 1, Price: 0},
+		},
+		Lines: []map[string]interface{}{
+			{"sku": "C3", "qty": 5},
+		},
+		Tags:   [2]string{"vip", "new"},
+		Codes:  []*int{nil, &code},
+		Status: "paid",
+	}
+	tests := []struct {
+		name        string
+		query       string
+		wantIsValid bool
+		wantErr     bool
+	}{
+		{
+			name:        "Normal case - any over structs",
+			query:       `any(items, sku = "A1" && qty > 2)`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - any not matched by a single element",
+			query:       `any(items, sku = "B2" && qty > 2)`,
+			wantIsValid: false,
+		},
+		{
+			name:        "Normal case - all over structs",
+			query:       `all(items, price > 0) || all(items, qty >= 1)`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - none over maps",
+			query:       `none(lines, qty > 10) && any(lines, sku = "C3")`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - scalars and nil elements",
+			query:       `any(tags, tags = "vip") && !none(codes, codes = 7) && status = "paid"`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - empty slice",
+			query:       `all(empty, qty > 0) && none(empty, qty > 0) && !any(empty, qty > 0)`,
+			wantIsValid: true,
+		},
+		{
+			name:    "Error case - not a slice",
+			query:   `any(status, status = "paid")`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := GenerateCondition(tt.query)
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			gotIsValid, err := Validate(condition, data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Condition.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotIsValid != tt.wantIsValid {
+				t.Errorf("Condition.Validate() gotIsValid = %v, want %v", gotIsValid, tt.wantIsValid)
+			}
+		})
+	}
+}
+
+func TestCondition_ValidateAggregate(t *testing.T) {
+	type item struct {
+		SKU   string   `json:"sku"`
+		Price int      `json:"price"`
+		Tax   *float64 `json:"tax"`
+	}
+	type object struct {
+		Items   []item                   `json:"items"`
+		Ratings []map[string]interface{} `json:"ratings"`
+		Scores  []float64                `json:"scores"`
+		Empty   []item                   `json:"empty"`
+		Status  string                   `json:"status"`
+	}
+	tax := 1.5
+	data := object{
+		Items: []item{
+			{SKU: "A1", Price: 300000, Tax: &tax},
+			{SKU: "B2", Price: 150000},
+			{SKU: "C3", Price: 100000, Tax: &tax},
+		},
+		Ratings: []map[string]interface{}{
+			{"score": 2},
+			{"score": 2.5},
+			{"comment": "no score"},
+		},
+		Scores: []float64{1, 4.5},
+		Status: "paid",
+	}
+	tests := []struct {
+		name        string
+		query       string
+		wantIsValid bool
+		wantErr     bool
+	}{
+		{
+			name:        "Normal case - sum and count over structs",
+			query:       `sum(items.price) > 500000 && count(items) >= 3`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - nil fields are skipped",
+			query:       `count(items.tax) = 2 && sum(items.tax) = 3 && avg(items.tax) = 1.5`,
 			wantIsValid: true,
-			wantErr:     false,
 		},
 		{
-			name: "Normal case - struct validation",
-			args: args{
-				query: `ID=1 &&  MemberID=2  &&   (Division=engineering || Division=finance)`,
-				object: struct {
-					ID       int
-					MemberID int
-					Division string
-				}{
-					ID:       1,
-					MemberID: 2,
-					Division: "engineering",
-				},
-			},
+			name:        "Normal case - avg over maps",
+			query:       `avg(ratings.score) < 2.5 && count(ratings.score) = 2`,
 			wantIsValid: true,
-			wantErr:     false,
 		},
 		{
-			name: "Normal case - struct validation - Brand attribute is not exist",
-			args: args{
-				query: `ID=1 &&  MemberID=2  &&   (Division=engineering || Division=finance) && Brand=Adidas`,
-				object: struct {
-					ID       int
-					MemberID int
-					Division string
-				}{
-					ID:       1,
-					MemberID: 2,
-					Division: "engineering",
-				},
-			},
-			wantIsValid: false,
-			wantErr:     false,
+			name:        "Normal case - min and max over scalars",
+			query:       `min(scores) = 1 && max(scores) + 0.5 = 5`,
+			wantIsValid: true,
 		},
 		{
-			name: "Normal case - struct validation - skip not exist attribute because using OR condition",
-			args: args{
-				query: `ID=1 &&  MemberID=2  &&   (Division=engineering || Division=finance) && (Category=Bawahan || ID=1 || Brand=nike)`,
-				object: struct {
-					ID       int
-					MemberID int
-					Division string
-				}{
-					ID:       1,
-					MemberID: 2,
-					Division: "engineering",
-				},
-			},
+			name:        "Normal case - empty collection",
+			query:       `count(empty) = 0 && sum(empty.price) = 0`,
 			wantIsValid: true,
-			wantErr:     false,
 		},
 		{
-			name: "Error case",
-			args: args{
-				query:  `ID=1 &&  MemberID=2  &&   (Division=engineering || Division=finance)`,
-				object: nil,
-			},
+			name:        "Normal case - avg of empty collection",
+			query:       `avg(empty.price) >= 0`,
 			wantIsValid: false,
-			wantErr:     true,
+		},
+		{
+			name:    "Error case - non collection",
+			query:   `count(status) > 0`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - non numeric field",
+			query:   `sum(items.sku) > 0`,
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			condition, _ := GenerateCondition(tt.args.query)
-			gotIsValid, err := Validate(condition, tt.args.object)
+			condition, err := GenerateCondition(tt.query)
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			gotIsValid, err := Validate(condition, data)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Condition.Validate() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if gotIsValid != tt.wantIsValid {
-				t.Errorf("Condition.Validate() = %v, want %v", gotIsValid, tt.wantIsValid)
+				t.Errorf("Condition.Validate() gotIsValid = %v, want %v", gotIsValid, tt.wantIsValid)
+			}
+		})
+	}
+}
+
+func TestGenerateQueryConditionReference(t *testing.T) {
+	err := RegisterCondition("tech_divisions", "division=engineering || division=finance")
+	if err != nil {
+		t.Fatalf("RegisterCondition() error = %v", err)
+	}
+	err = RegisterCondition("senior_tech", "@tech_divisions && level >= 5")
+	if err != nil {
+		t.Fatalf("RegisterCondition() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "Normal case",
+			query: "@tech_divisions && member_id > 100",
+			want:  "select * from data_member WHERE ( division = 'engineering' OR division = 'finance' ) AND member_id > 100",
+		},
+		{
+			name:  "Normal case - nested and negated reference",
+			query: "status = 1 && !@senior_tech",
+			want:  "select * from data_member WHERE status = 1 AND NOT ( ( division = 'engineering' OR division = 'finance' ) AND level >= 5 )",
+		},
+		{
+			name:    "Error case - undefined reference",
+			query:   "@unknown_divisions && member_id > 100",
+			wantErr: true,
+		},
+	}
+	var rgx = regexp.MustCompile(`[\s]{2,}`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := GenerateCondition(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GenerateCondition() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			got, err := GenerateQuery("select * from data_member", types.BaseCondition{
+				Conditions: []*types.Condition{&condition},
+			})
+			if err != nil {
+				t.Fatalf("GenerateQuery() error = %v", err)
+			}
+			strGot := strings.TrimSpace(rgx.ReplaceAllString(got, " "))
+			if !strings.EqualFold(strGot, tt.want) {
+				t.Errorf("GenerateQuery() got = %v, want %v", strGot, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+		limits    types.Limits
+		wantErr   *types.LimitError
+	}{
+		{
+			name:      "Normal case",
+			condition: `{"conditions":[{"attribute":{"name":"id","operator":"IN","value":"1,2,3"}},{"operator":"AND","conditions":[{"attribute":{"name":"name","operator":"=","value":"john"}}]}]}`,
+			limits:    types.Limits{MaxDepth: 2, MaxTokens: 4, MaxListSize: 3, MaxQueryLength: 5},
+		},
+		{
+			name:      "Error case - depth",
+			condition: `{"conditions":[{"conditions":[{"conditions":[{"attribute":{"name":"id","value":"1"}}]}]}]}`,
+			limits:    types.Limits{MaxDepth: 2},
+			wantErr:   &types.LimitError{Limit: "nesting depth", Max: 2},
+		},
+		{
+			name:      "Error case - depth of function arguments",
+			condition: `{"attribute":{"left":{"function":"lower","arguments":[{"function":"trim","arguments":[{"name":"name"}]}]},"operator":"=","value":"x"}}`,
+			limits:    types.Limits{MaxDepth: 1},
+			wantErr:   &types.LimitError{Limit: "nesting depth", Max: 1},
+		},
+		{
+			name:      "Error case - conditions and expressions",
+			condition: `{"conditions":[{"attribute":{"name":"id","value":"1"}},{"operator":"AND","attribute":{"left":{"operator":"+","left":{"name":"a"},"right":{"name":"b"}},"operator":">","value":"1"}}]}`,
+			limits:    types.Limits{MaxTokens: 5},
+			wantErr:   &types.LimitError{Limit: "token count", Max: 5},
+		},
+		{
+			name:      "Error case - list size",
+			condition: `{"attribute":{"name":"id","operator":"NOT IN","value":"1,2,3"}}`,
+			limits:    types.Limits{MaxListSize: 2},
+			wantErr:   &types.LimitError{Limit: "list size", Max: 2},
+		},
+		{
+			name:      "Error case - value length",
+			condition: `{"attribute":{"name":"name","operator":"=","value":"John Doe"}}`,
+			limits:    types.Limits{MaxQueryLength: 4},
+			wantErr:   &types.LimitError{Limit: "query length", Max: 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var condition types.Condition
+			if err := json.Unmarshal([]byte(tt.condition), &condition); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			err := CheckLimits(condition, tt.limits)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("CheckLimits() error = %v", err)
+				}
+				return
+			}
+			var limitErr *types.LimitError
+			if !errors.As(err, &limitErr) || *limitErr != *tt.wantErr {
+				t.Errorf("CheckLimits() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQuantifierWithoutCondition(t *testing.T) {
+	var condition types.Condition
+	if err := json.Unmarshal([]byte(`{"conditions":[{"quantifier":{"operator":"any","name":"items"}}]}`), &condition); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	wantErr := "invalid parameter, quantifier condition is required"
+	if err := CheckLimits(condition, types.Limits{}); err == nil || err.Error() != wantErr {
+		t.Errorf("CheckLimits() error = %v, want %v", err, wantErr)
+	}
+	if _, err := Validate(condition, map[string]interface{}{"items": []int{1}}); err == nil || err.Error() != wantErr {
+		t.Errorf("Validate() error = %v, want %v", err, wantErr)
+	}
+	if _, err := Bind(condition, nil); err == nil || err.Error() != wantErr {
+		t.Errorf("Bind() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestCondition_ValidateRegexPatterns(t *testing.T) {
+	data := map[string]interface{}{"code": "PRM-7"}
+	for round := 0; round < 2; round++ {
+		for i := 0; i < 300; i++ {
+			condition, err := GenerateCondition(fmt.Sprintf(`code ~ "^PRM-%d$"`, i))
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			isValid, err := Validate(condition, data)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if isValid != (i == 7) {
+				t.Errorf("Validate() pattern %d = %v, want %v", i, isValid, i == 7)
+			}
+		}
+	}
+}
+
+func TestGenerateConditionWithDialect(t *testing.T) {
+	dialect := structgen.DefaultDialect()
+	delete(dialect.Operators, "=")
+	dialect.Operators["=="] = consts.OperatorEqual
+	dialect.Operators["<>"] = consts.OperatorNotEqual
+	tests := []struct {
+		name    string
+		dialect structgen.Dialect
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Normal case",
+			dialect: dialect,
+			query:   "status == paid and (channel == web or channel <> app)",
+			want:    "select * from data_member WHERE status = 'paid' AND ( channel = 'web' OR channel != 'app' )",
+		},
+		{
+			name:    "Error case - operator of the default dialect",
+			dialect: dialect,
+			query:   "status = paid",
+			wantErr: true,
+		},
+		{
+			name:    "Error case - invalid dialect",
+			dialect: structgen.Dialect{Operators: map[string]string{"eq": consts.OperatorEqual}},
+			query:   "status eq paid",
+			wantErr: true,
+		},
+	}
+	var rgx = regexp.MustCompile(`[\s]{2,}`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := GenerateConditionWithDialect(tt.query, tt.dialect)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GenerateConditionWithDialect() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			got, err := GenerateQuery("select * from data_member", types.BaseCondition{
+				Conditions: []*types.Condition{&condition},
+			})
+			if err != nil {
+				t.Fatalf("GenerateQuery() error = %v", err)
+			}
+			strGot := strings.TrimSpace(rgx.ReplaceAllString(got, " "))
+			if !strings.EqualFold(strGot, tt.want) {
+				t.Errorf("GenerateQuery() got = %v, want %v", strGot, tt.want)
 			}
 		})
 	}
@@ -668,6 +1839,97 @@ func TestCondition_ValidateCondition(t *testing.T) {
 			wantIsValid:    true,
 			wantErr:        false,
 		},
+		{
+			name:           "Normal case - not equal operator",
+			referenceQuery: "id=1 && member_id!=45",
+			input:          "id=1 && member_id=44",
+			wantIsValid:    true,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - include operator",
+			referenceQuery: "id=1 && segment IN (trial, free)",
+			input:          "id=1 && (segment=paid || segment=FREE)",
+			wantIsValid:    true,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - exclude operator",
+			referenceQuery: "id=1 && segment NOT IN (trial, free)",
+			input:          "id=1 && segment=trial",
+			wantIsValid:    false,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - null operator",
+			referenceQuery: "id=1 && coupon IS NULL",
+			input:          "id=1",
+			wantIsValid:    true,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - not null operator",
+			referenceQuery: "id=1 && coupon IS NOT NULL",
+			input:          "id=1 && coupon IS NULL",
+			wantIsValid:    false,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - string pattern operator",
+			referenceQuery: `name LIKE "budi%" && brand CONTAINS ava`,
+			input:          "name=Budiman && brand=Arava",
+			wantIsValid:    true,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - regex operator",
+			referenceQuery: `id=1 && code ~ "^PRM-[0-9]+$"`,
+			input:          "id=1 && (code=PRM-12 || code=abc)",
+			wantIsValid:    true,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - negated reference group",
+			referenceQuery: "id=1 && !(segment=trial || segment=free)",
+			input:          "id=1 && segment=paid",
+			wantIsValid:    true,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - negated input condition",
+			referenceQuery: "id=1 && segment=trial",
+			input:          "id=1 && !segment=trial",
+			wantIsValid:    false,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - attribute reference",
+			referenceQuery: "used_quota < $max_quota",
+			input:          "used_quota=4 && max_quota=5",
+			wantIsValid:    true,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - missing attribute reference",
+			referenceQuery: "used_quota < $max_quota",
+			input:          "used_quota=4",
+			wantIsValid:    false,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - range",
+			referenceQuery: "id=1 && price BETWEEN 100 AND 500",
+			input:          "id=1 && price=250",
+			wantIsValid:    true,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - outside range",
+			referenceQuery: "id=1 && price NOT BETWEEN 100 AND 500",
+			input:          "id=1 && price=250",
+			wantIsValid:    false,
+			wantErr:        false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -928,6 +2190,27 @@ func TestCondition_FilterSlice(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Normal case - null operator",
+			args: args{
+				query:   "member_id=25 && leave_date IS NULL",
+				objects: testData,
+			},
+			wantResults: []Account{
+				{
+					ID:        5,
+					MemberID:  25,
+					Division:  "engineering",
+					Score:     fInt(100),
+					Point:     fInt64(3000),
+					Wallet:    fFloat(100),
+					Money:     fFloat64(1500000),
+					JoinDate:  time.Date(2015, 10, 9, 0, 0, 0, 0, time.UTC),
+					LeaveDate: nil,
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "Normal case - empty",
 			args: args{
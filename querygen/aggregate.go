@@ -0,0 +1,60 @@
+package querygen
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"strconv"
+	"strings"
+)
+
+// aggregateSQLMap holds the SQL form of the aggregates, where %s is replaced by
+// the aggregated element value.
+var aggregateSQLMap = map[string]string{
+	consts.AggregateCount: "COUNT(%s)",
+	consts.AggregateSum:   "COALESCE(SUM(%s), 0)",
+	consts.AggregateAvg:   "AVG(%s)",
+	consts.AggregateMin:   "MIN(%s)",
+	consts.AggregateMax:   "MAX(%s)",
+}
+
+// assignQueryAggregate renders an aggregate as a scalar subquery over the
+// elements of a JSON array column, which is only supported by the Postgres
+// dialect. The first part of the name is the column, the rest is the field
+// read from each element.
+func (g *queryBuilder) assignQueryAggregate(expression *types.Expression) (string, error) {
+	sql, ok := aggregateSQLMap[expression.Aggregate]
+	if !ok {
+		return "", fmt.Errorf(consts.ErrorMessageInvalidArgument, expression.Aggregate, "aggregate")
+	}
+	if g.Dialect != DialectPostgres {
+		return "", fmt.Errorf(consts.ErrorMessageUnsupportedOperator, expression.Aggregate, g.Dialect)
+	}
+
+	name, field := expression.Name, ""
+	if index := strings.Index(name, "."); index >= 0 {
+		name, field = name[:index], name[index+1:]
+	}
+	element := queryElement{
+		alias: "element" + strconv.Itoa(len(g.elements)+1),
+		name:  name,
+	}
+	source := g.assignQueryArraySource(name)
+
+	g.elements = append(g.elements, element)
+	defer func() {
+		g.elements = g.elements[:len(g.elements)-1]
+	}()
+
+	if field == "" {
+		field = name
+	}
+	var valueType valuetype.ValueType
+	if expression.Aggregate != consts.AggregateCount {
+		valueType = valuetype.Numeric
+	}
+	value := g.assignQueryColumn(field, valueType)
+	return "(SELECT " + fmt.Sprintf(sql, value) + " FROM jsonb_array_elements(" + source + ") AS " +
+		element.alias + "(value))", nil
+}
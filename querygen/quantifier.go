@@ -0,0 +1,100 @@
+package querygen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"strconv"
+	"strings"
+)
+
+// queryElement is a quantified JSON array being rendered, whose elements are
+// read through alias.
+type queryElement struct {
+	alias string
+	name  string
+}
+
+// quantifierSQLMap holds the SQL form of the quantifiers, where %[1]s is
+// replaced by the element subquery and %[2]s by the condition on an element.
+var quantifierSQLMap = map[string]string{
+	consts.QuantifierAny:  "EXISTS (%[1]s WHERE %[2]s)",
+	consts.QuantifierAll:  "NOT EXISTS (%[1]s WHERE (%[2]s) IS NOT TRUE)",
+	consts.QuantifierNone: "NOT EXISTS (%[1]s WHERE %[2]s)",
+}
+
+// assignQueryQuantifier renders a quantifier as an EXISTS subquery over the
+// elements of a JSON array column, which is only supported by the Postgres
+// dialect. Attributes of the quantified condition are read from the element
+// and cast by their type, an attribute named after the array itself reads a
+// scalar element.
+func (g *queryBuilder) assignQueryQuantifier(condition *types.Condition) (string, error) {
+	quantifier := condition.Quantifier
+	if _, err := assignLogicalOperator(condition); err != nil {
+		return "", err
+	}
+	sql, ok := quantifierSQLMap[quantifier.Operator]
+	if !ok {
+		return "", errors.New(fmt.Sprintf("Invalid operator: %s", quantifier.Operator))
+	}
+	if g.Dialect != DialectPostgres {
+		return "", fmt.Errorf(consts.ErrorMessageUnsupportedOperator, quantifier.Operator, g.Dialect)
+	}
+	if quantifier.Condition == nil {
+		return "", fmt.Errorf(consts.ErrorMessageInvalidParameter, "quantifier condition")
+	}
+
+	source := g.assignQueryArraySource(quantifier.Name)
+	element := queryElement{
+		alias: "element" + strconv.Itoa(len(g.elements)+1),
+		name:  quantifier.Name,
+	}
+	g.elements = append(g.elements, element)
+	defer func() {
+		g.elements = g.elements[:len(g.elements)-1]
+	}()
+
+	var buffer bytes.Buffer
+	err := g.buildWhereParameter([]*types.Condition{quantifier.Condition}, &buffer, true, false)
+	if err != nil {
+		return "", err
+	}
+	subquery := "SELECT 1 FROM jsonb_array_elements(" + source + ") AS " + element.alias + "(value)"
+	return fmt.Sprintf(sql, subquery, strings.TrimSpace(buffer.String())), nil
+}
+
+// assignQueryArraySource renders the JSON array named name, a column or a field
+// of the enclosing quantified element.
+func (g *queryBuilder) assignQueryArraySource(name string) string {
+	if len(g.elements) == 0 {
+		return name
+	}
+	element := g.elements[len(g.elements)-1]
+	return element.alias + ".value->" + assignValueByAttributeType(valuetype.Alphanumeric, name)
+}
+
+// assignQueryColumn renders the attribute name, reading it from the innermost
+// quantified element if any. Values read from an element are text unless
+// valueType calls for a cast.
+func (g *queryBuilder) assignQueryColumn(name string, valueType valuetype.ValueType) string {
+	if len(g.elements) == 0 {
+		return name
+	}
+	element := g.elements[len(g.elements)-1]
+	column := element.alias + ".value->>" + assignValueByAttributeType(valuetype.Alphanumeric, name)
+	if name == element.name {
+		column = element.alias + ".value #>> '{}'"
+	}
+	switch valueType {
+	case valuetype.Numeric:
+		return "(" + column + ")::numeric"
+	case valuetype.Boolean:
+		return "(" + column + ")::boolean"
+	case valuetype.Date:
+		return "(" + column + ")::timestamp"
+	}
+	return column
+}
@@ -6,11 +6,48 @@ import (
 	"fmt"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/registry"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/utils"
+	"reflect"
+	"strconv"
 	"strings"
+	"time"
 )
 
+type Dialect string
+
+const (
+	DialectMySQL    Dialect = "mysql"
+	DialectPostgres Dialect = "postgres"
+)
+
+// QueryGen generates SQL queries. Operators without a standard SQL form, like
+// the regular expression match, are only rendered when Dialect is set. Custom
+// functions and operators are rendered with the SQL template of their
+// registration in Registry, registry.Default when nil. Relative dates like
+// now() - 7d are rendered as SQL of the dialect, or resolved to timestamps from
+// the Now clock when it's set. Quantifiers like any(items, qty > 2) and
+// aggregates like sum(items.price) are rendered as subqueries over JSON array
+// columns with the Postgres dialect. Resolved timestamps are rendered in
+// Location, the default location of utils when nil. A QueryGen isn't changed
+// by generating queries, so it can be shared by concurrent calls.
 type QueryGen struct {
+	Dialect  Dialect
+	Registry *registry.Registry
+	Now      func() time.Time
+	Location *time.Location
+}
+
+// queryBuilder holds the state of a single query being generated: the bound
+// parameters, the driver arguments and the quantified JSON arrays.
+type queryBuilder struct {
+	*QueryGen
+
+	params   map[string]interface{}
+	args     []interface{}
+	isBound  bool
+	elements []queryElement
 }
 
 var (
@@ -23,27 +60,108 @@ var (
 		consts.OperatorGreaterThanEqual: nil,
 		consts.OperatorInclude:          nil,
 		consts.OperatorExclude:          nil,
+		consts.OperatorBetween:          nil,
+		consts.OperatorNotBetween:       nil,
 		consts.OperatorIsNull:           nil,
 		consts.OperatorIsNotNull:        nil,
 		consts.OperatorLike:             nil,
+		consts.OperatorStartsWith:       nil,
+		consts.OperatorEndsWith:         nil,
+		consts.OperatorContains:         nil,
+		consts.OperatorRegex:            nil,
 	}
 
 	logicalOperatorMap = map[string]interface{}{
 		consts.LogicalOperatorAnd: nil,
 		consts.LogicalOperatorOr:  nil,
 	}
+
+	arithmeticOperatorMap = map[string]int{
+		consts.ArithmeticOperatorAdd:      1,
+		consts.ArithmeticOperatorSubtract: 1,
+		consts.ArithmeticOperatorMultiply: 2,
+		consts.ArithmeticOperatorDivide:   2,
+		consts.ArithmeticOperatorModulo:   2,
+	}
+
+	// functionMap holds the standard SQL form of the functions, where %s is
+	// replaced by the comma separated arguments. len counts the characters of
+	// a string, the elements of a collection are counted by count.
+	functionMap = map[string]string{
+		consts.FunctionLower:    "LOWER(%s)",
+		consts.FunctionUpper:    "UPPER(%s)",
+		consts.FunctionTrim:     "TRIM(%s)",
+		consts.FunctionLen:      "CHAR_LENGTH(%s)",
+		consts.FunctionAbs:      "ABS(%s)",
+		consts.FunctionYear:     "EXTRACT(YEAR FROM %s)",
+		consts.FunctionMonth:    "EXTRACT(MONTH FROM %s)",
+		consts.FunctionDay:      "EXTRACT(DAY FROM %s)",
+		consts.FunctionCoalesce: "COALESCE(%s)",
+
+		consts.FunctionNow:          "CURRENT_TIMESTAMP",
+		consts.FunctionToday:        "CURRENT_DATE",
+		consts.FunctionStartOfMonth: "CURRENT_DATE - (EXTRACT(DAY FROM CURRENT_DATE) - 1) * INTERVAL '1' DAY",
+	}
+
+	dialectFunctionMap = map[Dialect]map[string]string{
+		DialectMySQL: {
+			consts.FunctionYear:  "YEAR(%s)",
+			consts.FunctionMonth: "MONTH(%s)",
+			consts.FunctionDay:   "DAY(%s)",
+
+			consts.FunctionNow:          "NOW()",
+			consts.FunctionToday:        "CURDATE()",
+			consts.FunctionStartOfMonth: "DATE_SUB(CURDATE(), INTERVAL DAYOFMONTH(CURDATE()) - 1 DAY)",
+		},
+		DialectPostgres: {
+			consts.FunctionNow:          "NOW()",
+			consts.FunctionStartOfMonth: "DATE_TRUNC('month', CURRENT_DATE)",
+		},
+	}
+
+	durationUnitMap = map[string]string{
+		consts.DurationUnitSecond: "SECOND",
+		consts.DurationUnitMinute: "MINUTE",
+		consts.DurationUnitHour:   "HOUR",
+		consts.DurationUnitDay:    "DAY",
+	}
+
+	likePatternReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
 )
 
 func (g *QueryGen) GenerateQuery(mainQuery string, baseCondition types.BaseCondition) (string, error) {
+	builder := queryBuilder{QueryGen: g}
+	return builder.generateQuery(mainQuery, baseCondition)
+}
+
+// GenerateQueryWithArgs generates a SQL query like GenerateQuery, rendering
+// parameters as placeholders and returning their values from params as the
+// driver arguments. Postgres uses numbered placeholders, other dialects ?.
+// Relative dates resolved from the Now clock are bound as arguments too.
+func (g *QueryGen) GenerateQueryWithArgs(mainQuery string, baseCondition types.BaseCondition, params map[string]interface{}) (string, []interface{}, error) {
+	builder := queryBuilder{QueryGen: g, params: params, isBound: true}
+	query, err := builder.generateQuery(mainQuery, baseCondition)
+	if err != nil {
+		return "", nil, err
+	}
+	return query, builder.args, nil
+}
+
+func (g *QueryGen) generateWhereParameter(conditions []*types.Condition) (string, error) {
+	builder := queryBuilder{QueryGen: g}
+	return builder.generateWhereParameter(conditions)
+}
+
+func (g *queryBuilder) generateQuery(mainQuery string, baseCondition types.BaseCondition) (string, error) {
 	queries := strings.Split(strings.ToLower(mainQuery), " from ")
 	if len(queries) > 1 && baseCondition.Fields != nil && len(baseCondition.Fields) > 0 {
 		mainQuery = "SELECT " + strings.Trim(strings.Join(baseCondition.Fields, ", "), "[]") + " FROM " + queries[1]
 	}
-	return generateQueryParameter(mainQuery, baseCondition)
+	return g.generateQueryParameter(mainQuery, baseCondition)
 }
 
-func generateQueryParameter(mainQuery string, baseCondition types.BaseCondition) (string, error) {
-	conditionQuery, err := generateWhereParameter(baseCondition.Conditions)
+func (g *queryBuilder) generateQueryParameter(mainQuery string, baseCondition types.BaseCondition) (string, error) {
+	conditionQuery, err := g.generateWhereParameter(baseCondition.Conditions)
 	if err != nil {
 		return "", err
 	}
@@ -53,14 +171,14 @@ func generateQueryParameter(mainQuery string, baseCondition types.BaseCondition)
 	return mainQuery, nil
 }
 
-func generateWhereParameter(conditions []*types.Condition) (string, error) {
+func (g *queryBuilder) generateWhereParameter(conditions []*types.Condition) (string, error) {
 	var queryBuffer bytes.Buffer
 	for i, condition := range conditions {
 		isFirst := false
 		if i == 0 {
 			isFirst = true
 		}
-		err := buildWhereParameter(condition.Conditions, &queryBuffer, false, isFirst)
+		err := g.buildWhereParameter(condition.Conditions, &queryBuffer, false, isFirst)
 		if err != nil {
 			return "", err
 		}
@@ -88,7 +206,7 @@ func generateSortLimit(page int, limit int, sort map[string]string) (string, str
 	return querySort, queryLimit
 }
 
-func buildWhereParameter(conditions []*types.Condition, queryBuffer *bytes.Buffer, isGroup, isFirst bool) error {
+func (g *queryBuilder) buildWhereParameter(conditions []*types.Condition, queryBuffer *bytes.Buffer, isGroup, isFirst bool) error {
 	logicalOperator := "WHERE"
 	if isGroup {
 		logicalOperator = ""
@@ -99,18 +217,22 @@ func buildWhereParameter(conditions []*types.Condition, queryBuffer *bytes.Buffe
 			conditionLength := len(condition.Conditions)
 			if conditionLength > 0 {
 				var operator string
-				enableGroup := conditionLength > 1
+				enableGroup := conditionLength > 1 || condition.Negate
 				operator = condition.Operator
 				if !isGroup && i == 0 && isFirst {
 					operator = "WHERE"
 				}
 				var buffer bytes.Buffer
-				err := buildWhereParameter(condition.Conditions, &buffer, true, isFirst)
+				err := g.buildWhereParameter(condition.Conditions, &buffer, true, isFirst)
 				if err != nil {
-					continue
+					return err
 				}
 
 				queryBuffer.WriteString(operator)
+				if condition.Negate {
+					queryBuffer.WriteByte(' ')
+					queryBuffer.WriteString(consts.LogicalOperatorNot)
+				}
 				if enableGroup {
 					queryBuffer.WriteByte(' ')
 					queryBuffer.WriteByte('(')
@@ -124,48 +246,381 @@ func buildWhereParameter(conditions []*types.Condition, queryBuffer *bytes.Buffe
 			}
 		}
 
-		if condition.Attribute == nil {
+		var (
+			comparison string
+			err        error
+		)
+		switch {
+		case condition.Quantifier != nil:
+			comparison, err = g.assignQueryQuantifier(condition)
+		case condition.Attribute != nil:
+			comparison, err = g.assignQueryComparison(condition)
+		default:
 			continue
 		}
-		err := assignAndValidateOperator(condition)
-		if err != nil {
-			return err
-		}
-		queryValue, err := assignQueryValue(condition.Attribute)
 		if err != nil {
 			return err
 		}
 		if i > 0 {
-			logicalOperator = condition.Operator
+			logicalOperator, err = assignLogicalOperator(condition)
+			if err != nil {
+				return err
+			}
 		}
 
 		queryBuffer.WriteString(logicalOperator)
 		queryBuffer.WriteByte(' ')
-		queryBuffer.WriteString(condition.Attribute.Name)
-		queryBuffer.WriteByte(' ')
-		queryBuffer.WriteString(condition.Attribute.Operator)
-		queryBuffer.WriteByte(' ')
-		queryBuffer.WriteString(queryValue)
+		if condition.Negate {
+			queryBuffer.WriteString(consts.LogicalOperatorNot)
+			queryBuffer.WriteString(" (")
+		}
+		queryBuffer.WriteString(comparison)
+		if condition.Negate {
+			queryBuffer.WriteByte(')')
+		}
 		queryBuffer.WriteByte(' ')
 	}
 	return nil
 }
 
-func assignQueryValue(attribute *types.Attribute) (value string, err error) {
+// assignQueryComparison renders the comparison of a condition attribute.
+func (g *queryBuilder) assignQueryComparison(condition *types.Condition) (string, error) {
+	attribute, err := g.assignAndValidateOperator(condition)
+	if err != nil {
+		return "", err
+	}
+	attribute, param, err := g.resolveParameter(attribute)
+	if err != nil {
+		return "", err
+	}
+	queryOperator, err := g.assignQueryOperator(attribute)
+	if err != nil {
+		return "", err
+	}
+	queryName, err := g.assignQueryName(attribute)
+	if err != nil {
+		return "", err
+	}
+	var queryValue string
+	switch {
+	case attribute.Right != nil:
+		queryValue, err = g.assignQueryExpression(attribute.Right)
+	case attribute.Type == valuetype.Parameter:
+		queryValue, err = g.assignQueryArgument(attribute, param)
+	default:
+		queryValue, err = g.assignQueryValue(attribute)
+	}
+	if err != nil {
+		return "", err
+	}
+	comparison := queryName + " " + queryOperator
+	if queryValue != "" {
+		comparison += " " + queryValue
+	}
+	if operator, ok := g.getRegistry().Operator(attribute.Operator); ok {
+		if operator.SQL == "" {
+			return "", fmt.Errorf(consts.ErrorMessageMissingSQLTemplate, operator.Name)
+		}
+		comparison = fmt.Sprintf(operator.SQL, queryName, queryValue)
+	}
+	return comparison, nil
+}
+
+func (g *queryBuilder) assignQueryValue(attribute *types.Attribute) (value string, err error) {
 	if attribute == nil {
 		return "", fmt.Errorf(consts.ErrorMessageInvalidParameter, "attribute")
 	}
 	switch attribute.Operator {
 	case consts.OperatorInclude, consts.OperatorExclude:
 		value = "(" + assignCollectionValueByAttributeType(attribute.Type, attribute.Value) + ")"
+	case consts.OperatorBetween, consts.OperatorNotBetween:
+		bounds := strings.Split(attribute.Value, ",")
+		if len(bounds) != 2 {
+			return "", fmt.Errorf(consts.ErrorMessageInvalidParameter, "range bounds")
+		}
+		value = assignValueByAttributeType(attribute.Type, bounds[0]) + " " + consts.LogicalOperatorAnd + " " +
+			assignValueByAttributeType(attribute.Type, bounds[1])
 	case consts.OperatorIsNull, consts.OperatorIsNotNull:
 		value = ""
+	case consts.OperatorEqual, consts.OperatorNotEqual:
+		if attribute.Type != valuetype.Null {
+			value = assignValueByAttributeType(attribute.Type, attribute.Value)
+		}
+	case consts.OperatorStartsWith:
+		value = assignValueByAttributeType(valuetype.Alphanumeric, escapeLikePattern(attribute.Value)+"%")
+	case consts.OperatorEndsWith:
+		value = assignValueByAttributeType(valuetype.Alphanumeric, "%"+escapeLikePattern(attribute.Value))
+	case consts.OperatorContains:
+		value = assignValueByAttributeType(valuetype.Alphanumeric, "%"+escapeLikePattern(attribute.Value)+"%")
+	case consts.OperatorLike:
+		value = assignValueByAttributeType(valuetype.Alphanumeric, attribute.Value)
+	case consts.OperatorRegex:
+		pattern := attribute.Value
+		if g.Dialect == DialectMySQL {
+			pattern = strings.ReplaceAll(pattern, `\`, `\\`)
+		}
+		value = assignValueByAttributeType(valuetype.Alphanumeric, pattern)
 	default:
 		value = assignValueByAttributeType(attribute.Type, attribute.Value)
 	}
 	return
 }
 
+func (g *queryBuilder) assignQueryOperator(attribute *types.Attribute) (string, error) {
+	switch attribute.Operator {
+	case consts.OperatorEqual, consts.OperatorNotEqual:
+		if attribute.Type != valuetype.Null {
+			return attribute.Operator, nil
+		}
+		if attribute.Operator == consts.OperatorEqual {
+			return consts.OperatorIsNull, nil
+		}
+		return consts.OperatorIsNotNull, nil
+	case consts.OperatorStartsWith, consts.OperatorEndsWith, consts.OperatorContains:
+		return consts.OperatorLike, nil
+	case consts.OperatorRegex:
+		switch g.Dialect {
+		case DialectPostgres:
+			return consts.OperatorRegex, nil
+		case DialectMySQL:
+			return consts.OperatorRegexMySQL, nil
+		default:
+			return "", fmt.Errorf(consts.ErrorMessageUnsupportedOperator, attribute.Operator, g.Dialect)
+		}
+	default:
+		return attribute.Operator, nil
+	}
+}
+
+// resolveParameter returns the bound value of a parameter attribute. A nil
+// value is turned into the null literal, so it's rendered as IS NULL.
+func (g *queryBuilder) resolveParameter(attribute *types.Attribute) (*types.Attribute, interface{}, error) {
+	if attribute.Type != valuetype.Parameter {
+		return attribute, nil, nil
+	}
+	param, ok := g.params[attribute.Value]
+	if !ok {
+		return nil, nil, fmt.Errorf(consts.ErrorMessageUnboundParameter, attribute.Value)
+	}
+	if isNil(param) {
+		switch attribute.Operator {
+		case consts.OperatorEqual, consts.OperatorNotEqual:
+			return &types.Attribute{
+				Name:     attribute.Name,
+				Operator: attribute.Operator,
+				Value:    consts.LiteralNull,
+				Type:     valuetype.Null,
+			}, nil, nil
+		case consts.OperatorIsNull, consts.OperatorIsNotNull:
+		default:
+			return nil, nil, fmt.Errorf(consts.ErrorMessageInvalidBindValue, param, attribute.Value)
+		}
+	}
+	return attribute, param, nil
+}
+
+// assignQueryArgument adds param to the driver arguments and returns its
+// placeholder. A list bound to IN or NOT IN gets a placeholder per item.
+func (g *queryBuilder) assignQueryArgument(attribute *types.Attribute, param interface{}) (string, error) {
+	switch attribute.Operator {
+	case consts.OperatorIsNull, consts.OperatorIsNotNull:
+		return "", nil
+	case consts.OperatorInclude, consts.OperatorExclude:
+		rValue := reflect.ValueOf(param)
+		if rValue.Kind() != reflect.Slice && rValue.Kind() != reflect.Array {
+			return "(" + g.addQueryArgument(param) + ")", nil
+		}
+		if rValue.Len() == 0 {
+			return "", fmt.Errorf(consts.ErrorMessageInvalidBindValue, param, attribute.Value)
+		}
+		placeholders := make([]string, 0, rValue.Len())
+		for i := 0; i < rValue.Len(); i++ {
+			placeholders = append(placeholders, g.addQueryArgument(rValue.Index(i).Interface()))
+		}
+		return "(" + strings.Join(placeholders, ",") + ")", nil
+	case consts.OperatorStartsWith:
+		return g.addQueryArgument(escapeLikePattern(fmt.Sprint(param)) + "%"), nil
+	case consts.OperatorEndsWith:
+		return g.addQueryArgument("%" + escapeLikePattern(fmt.Sprint(param))), nil
+	case consts.OperatorContains:
+		return g.addQueryArgument("%" + escapeLikePattern(fmt.Sprint(param)) + "%"), nil
+	default:
+		return g.addQueryArgument(param), nil
+	}
+}
+
+func (g *queryBuilder) addQueryArgument(param interface{}) string {
+	g.args = append(g.args, param)
+	if g.Dialect == DialectPostgres {
+		return fmt.Sprintf("$%d", len(g.args))
+	}
+	return "?"
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	rValue := reflect.ValueOf(value)
+	switch rValue.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return rValue.IsNil()
+	}
+	return false
+}
+
+func (g *queryBuilder) assignQueryName(attribute *types.Attribute) (string, error) {
+	if attribute.Left != nil {
+		return g.assignQueryExpression(attribute.Left)
+	}
+	return g.assignQueryColumn(attribute.Name, attribute.Type), nil
+}
+
+// assignQueryExpression renders an arithmetic expression or a function call,
+// adding parentheses where the tree doesn't follow the SQL operator precedence.
+func (g *queryBuilder) assignQueryExpression(expression *types.Expression) (string, error) {
+	if g.Now != nil {
+		if timeValue, ok := resolveRelativeDate(expression, g.Now()); ok {
+			if g.isBound {
+				return g.addQueryArgument(timeValue), nil
+			}
+			return assignValueByAttributeType(valuetype.Date, utils.FormatTimeIn(timeValue, g.Location)), nil
+		}
+	}
+	if expression.Aggregate != "" {
+		return g.assignQueryAggregate(expression)
+	}
+	if expression.Function != "" {
+		return g.assignQueryFunction(expression)
+	}
+	if expression.Operator == "" {
+		if expression.Name != "" {
+			return g.assignQueryColumn(expression.Name, ""), nil
+		}
+		switch expression.Type {
+		case valuetype.Alphanumeric:
+			return assignValueByAttributeType(valuetype.Alphanumeric, expression.Value), nil
+		case valuetype.Duration:
+			return g.assignQueryInterval(expression.Value)
+		}
+		if _, err := strconv.ParseFloat(expression.Value, 64); err != nil {
+			return "", fmt.Errorf(consts.ErrorMessageInvalidType, "numeric")
+		}
+		return expression.Value, nil
+	}
+	precedence, ok := arithmeticOperatorMap[expression.Operator]
+	if !ok || expression.Left == nil || expression.Right == nil {
+		return "", errors.New(fmt.Sprintf("Invalid operator: %s", expression.Operator))
+	}
+	left, err := g.assignQueryExpression(expression.Left)
+	if err != nil {
+		return "", err
+	}
+	if expression.Left.Operator != "" && arithmeticOperatorMap[expression.Left.Operator] < precedence {
+		left = "(" + left + ")"
+	}
+	right, err := g.assignQueryExpression(expression.Right)
+	if err != nil {
+		return "", err
+	}
+	if expression.Right.Operator != "" && arithmeticOperatorMap[expression.Right.Operator] <= precedence {
+		right = "(" + right + ")"
+	}
+	return left + " " + expression.Operator + " " + right, nil
+}
+
+// assignQueryInterval renders a duration literal like 7d as an interval of the
+// dialect. Weeks are rendered as days, which every dialect supports.
+func (g *queryBuilder) assignQueryInterval(value string) (string, error) {
+	amount, unit, ok := utils.SplitDuration(value)
+	if !ok {
+		return "", fmt.Errorf(consts.ErrorMessageInvalidType, "duration")
+	}
+	if unit == consts.DurationUnitWeek {
+		amount, unit = amount*7, consts.DurationUnitDay
+	}
+	switch g.Dialect {
+	case DialectPostgres:
+		return fmt.Sprintf("INTERVAL '%d %s'", amount, strings.ToLower(durationUnitMap[unit])), nil
+	case DialectMySQL:
+		return fmt.Sprintf("INTERVAL %d %s", amount, durationUnitMap[unit]), nil
+	default:
+		return fmt.Sprintf("INTERVAL '%d' %s", amount, durationUnitMap[unit]), nil
+	}
+}
+
+// resolveRelativeDate computes the time of an expression made of the current
+// date functions shifted by durations, e.g. now() - 7d.
+func resolveRelativeDate(expression *types.Expression, now time.Time) (time.Time, bool) {
+	switch expression.Function {
+	case consts.FunctionNow:
+		return now, true
+	case consts.FunctionToday:
+		return utils.StartOfDay(now), true
+	case consts.FunctionStartOfMonth:
+		return utils.StartOfMonth(now), true
+	}
+	if expression.Left == nil || expression.Right == nil || expression.Right.Type != valuetype.Duration {
+		return time.Time{}, false
+	}
+	timeValue, ok := resolveRelativeDate(expression.Left, now)
+	if !ok {
+		return time.Time{}, false
+	}
+	duration, ok := utils.StringToDuration(expression.Right.Value)
+	if !ok {
+		return time.Time{}, false
+	}
+	switch expression.Operator {
+	case consts.ArithmeticOperatorAdd:
+		return timeValue.Add(duration), true
+	case consts.ArithmeticOperatorSubtract:
+		return timeValue.Add(-duration), true
+	}
+	return time.Time{}, false
+}
+
+// assignQueryFunction renders a function call with the SQL function of the
+// dialect, falling back to the standard SQL one. Registered functions are
+// rendered with their SQL template.
+func (g *queryBuilder) assignQueryFunction(expression *types.Expression) (string, error) {
+	function, ok := dialectFunctionMap[g.Dialect][expression.Function]
+	if !ok {
+		function, ok = functionMap[expression.Function]
+	}
+	customFunction, isCustom := g.getRegistry().Function(expression.Function)
+	if !ok && !isCustom {
+		return "", errors.New(fmt.Sprintf("Invalid function: %s", expression.Function))
+	}
+	if !ok && customFunction.SQL == "" {
+		return "", fmt.Errorf(consts.ErrorMessageMissingSQLTemplate, customFunction.Name)
+	}
+	arguments := make([]string, 0, len(expression.Arguments))
+	for _, argument := range expression.Arguments {
+		queryArgument, err := g.assignQueryExpression(argument)
+		if err != nil {
+			return "", err
+		}
+		arguments = append(arguments, queryArgument)
+	}
+	if !ok {
+		templateArguments := make([]interface{}, 0, len(arguments))
+		for _, argument := range arguments {
+			templateArguments = append(templateArguments, argument)
+		}
+		return fmt.Sprintf(customFunction.SQL, templateArguments...), nil
+	}
+	if len(arguments) == 0 {
+		return function, nil
+	}
+	return fmt.Sprintf(function, strings.Join(arguments, ", ")), nil
+}
+
+// escapeLikePattern escapes LIKE wildcards so value is matched literally.
+func escapeLikePattern(value string) string {
+	return likePatternReplacer.Replace(value)
+}
+
 func assignCollectionValueByAttributeType(attrType valuetype.ValueType, attrValue string) string {
 	if attrType != valuetype.Numeric {
 		attrs := strings.Split(attrValue, ",")
@@ -179,30 +634,60 @@ func assignCollectionValueByAttributeType(attrType valuetype.ValueType, attrValu
 }
 
 func assignValueByAttributeType(attrType valuetype.ValueType, attrValue string) string {
-	if attrType != valuetype.Numeric {
+	switch attrType {
+	case valuetype.Numeric, valuetype.Reference:
+	case valuetype.Boolean, valuetype.Null:
+		attrValue = strings.ToUpper(attrValue)
+	default:
 		attrValue = "'" + strings.ReplaceAll(attrValue, "'", "''") + "'"
 	}
 	return attrValue
 }
 
-func assignAndValidateOperator(condition *types.Condition) error {
-	if condition == nil || condition.Attribute == nil {
-		return fmt.Errorf(consts.ErrorMessageInvalidParameter, "condition")
+// assignLogicalOperator returns the logical operator joining condition to
+// the previous one, AND when it's empty.
+func assignLogicalOperator(condition *types.Condition) (string, error) {
+	operator := condition.Operator
+	if operator == "" {
+		operator = consts.LogicalOperatorAnd
 	}
-	if condition.Operator == "" {
-		condition.Operator = consts.LogicalOperatorAnd
+	if !isValidFilterLogicalOperator(operator) {
+		return "", errors.New(fmt.Sprintf("Invalid logical operator: %s", operator))
 	}
-	if !isValidFilterLogicalOperator(condition.Operator) {
-		return errors.New(fmt.Sprintf("Invalid logical operator: %s", condition.Operator))
+	return operator, nil
+}
+
+// assignAndValidateOperator returns the condition attribute with its
+// operator defaulted to equality. The condition itself isn't changed, so
+// it can be shared by concurrent calls.
+func (g *queryBuilder) assignAndValidateOperator(condition *types.Condition) (*types.Attribute, error) {
+	if condition == nil || condition.Attribute == nil {
+		return nil, fmt.Errorf(consts.ErrorMessageInvalidParameter, "condition")
+	}
+	if _, err := assignLogicalOperator(condition); err != nil {
+		return nil, err
 	}
 
-	if condition.Attribute.Operator == "" {
-		condition.Attribute.Operator = consts.OperatorEqual
+	attribute := condition.Attribute
+	if attribute.Operator == "" {
+		defaulted := *attribute
+		defaulted.Operator = consts.OperatorEqual
+		attribute = &defaulted
 	}
-	if !isValidFilterOperator(condition.Attribute.Operator) {
-		return errors.New(fmt.Sprintf("Invalid operator: %s", condition.Attribute.Operator))
+	if _, ok := g.getRegistry().Operator(attribute.Operator); ok {
+		return attribute, nil
 	}
-	return nil
+	if !isValidFilterOperator(attribute.Operator) {
+		return nil, errors.New(fmt.Sprintf("Invalid operator: %s", attribute.Operator))
+	}
+	return attribute, nil
+}
+
+func (g *QueryGen) getRegistry() *registry.Registry {
+	if g.Registry == nil {
+		return registry.Default
+	}
+	return g.Registry
 }
 
 func isValidFilterOperator(currOperator string) bool {
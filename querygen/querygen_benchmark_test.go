@@ -69,7 +69,8 @@ func BenchmarkGenerateWhereParameter(b *testing.B) {
 		},
 	}
 
+	g := QueryGen{}
 	for n := 0; n < b.N; n++ {
-		generateWhereParameter(req.args.condition)
+		g.generateWhereParameter(req.args.condition)
 	}
 }
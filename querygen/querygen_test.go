@@ -1,16 +1,21 @@
 package querygen
 
 import (
+	"fmt"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/registry"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 func Test_generateWhereParameter(t *testing.T) {
 	type args struct {
 		condition []*types.Condition
+		dialect   Dialect
 	}
 	tests := []struct {
 		name    string
@@ -414,11 +419,628 @@ func Test_generateWhereParameter(t *testing.T) {
 `,
 			wantErr: false,
 		},
+		{
+			name: "Normal case - string pattern operator",
+			args: args{
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Attribute: &types.Attribute{
+									Name:     "name",
+									Operator: "LIKE",
+									Value:    "PRM-%",
+								},
+							},
+							{
+								Operator: "AND",
+								Attribute: &types.Attribute{
+									Name:     "code",
+									Operator: "STARTS WITH",
+									Value:    "12",
+									Type:     valuetype.Numeric,
+								},
+							},
+							{
+								Operator: "AND",
+								Attribute: &types.Attribute{
+									Name:     "city",
+									Operator: "ENDS WITH",
+									Value:    "karta",
+									Type:     valuetype.Alphanumeric,
+								},
+							},
+							{
+								Operator: "AND",
+								Attribute: &types.Attribute{
+									Name:     "note",
+									Operator: "CONTAINS",
+									Value:    "50%_o'k",
+									Type:     valuetype.Alphanumeric,
+								},
+							},
+						},
+					},
+				},
+			},
+			want: `
+                WHERE 
+                  name LIKE 'PRM-%' 
+                  AND code LIKE '12%' 
+                  AND city LIKE '%karta' 
+                  AND note LIKE '%50\%\_o''k%'
+`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - regex operator - postgres",
+			args: args{
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Attribute: &types.Attribute{
+									Name:     "name",
+									Operator: "~",
+									Value:    `^PRM-\d+$`,
+									Type:     valuetype.Alphanumeric,
+								},
+							},
+						},
+					},
+				},
+				dialect: DialectPostgres,
+			},
+			want: `
+                WHERE 
+                  name ~ '^PRM-\d+$'
+`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - regex operator - mysql",
+			args: args{
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Attribute: &types.Attribute{
+									Name:     "name",
+									Operator: "~",
+									Value:    `^PRM-\d+$`,
+									Type:     valuetype.Alphanumeric,
+								},
+							},
+						},
+					},
+				},
+				dialect: DialectMySQL,
+			},
+			want: `
+                WHERE 
+                  name REGEXP '^PRM-\\d+$'
+`,
+			wantErr: false,
+		},
+		{
+			name: "Error case - regex operator without dialect",
+			args: args{
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Attribute: &types.Attribute{
+									Name:     "id",
+									Operator: "=",
+									Value:    "1",
+									Type:     valuetype.Numeric,
+								},
+							},
+							{
+								Operator: "AND",
+								Conditions: []*types.Condition{
+									{
+										Attribute: &types.Attribute{
+											Name:     "name",
+											Operator: "~",
+											Value:    "^PRM",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want:    ``,
+			wantErr: true,
+		},
+		{
+			name: "Normal case - negated condition",
+			args: args{
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Negate: true,
+								Attribute: &types.Attribute{
+									Name:     "id",
+									Operator: "=",
+									Value:    "1",
+									Type:     valuetype.Numeric,
+								},
+							},
+							{
+								Operator: "AND",
+								Negate:   true,
+								Conditions: []*types.Condition{
+									{
+										Attribute: &types.Attribute{
+											Name:     "division",
+											Operator: "=",
+											Value:    "engineering",
+											Type:     valuetype.Alphanumeric,
+										},
+									},
+									{
+										Operator: "OR",
+										Attribute: &types.Attribute{
+											Name:     "division",
+											Operator: "=",
+											Value:    "finance",
+											Type:     valuetype.Alphanumeric,
+										},
+									},
+								},
+							},
+							{
+								Operator: "OR",
+								Negate:   true,
+								Conditions: []*types.Condition{
+									{
+										Attribute: &types.Attribute{
+											Name:     "deleted_at",
+											Operator: "IS NULL",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: `
+                WHERE 
+                  NOT (id = 1) 
+                  AND NOT (
+                    division = 'engineering' 
+                    OR division = 'finance'
+                  ) 
+                  OR NOT ( deleted_at IS NULL )
+`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - boolean and null literal",
+			args: args{
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Attribute: &types.Attribute{
+									Name:     "is_active",
+									Operator: "=",
+									Value:    "true",
+									Type:     valuetype.Boolean,
+								},
+							},
+							{
+								Operator: "AND",
+								Attribute: &types.Attribute{
+									Name:     "deleted_at",
+									Operator: "=",
+									Value:    "null",
+									Type:     valuetype.Null,
+								},
+							},
+							{
+								Operator: "OR",
+								Attribute: &types.Attribute{
+									Name:     "archived_at",
+									Operator: "!=",
+									Value:    "null",
+									Type:     valuetype.Null,
+								},
+							},
+							{
+								Operator: "AND",
+								Attribute: &types.Attribute{
+									Name:     "code",
+									Operator: "=",
+									Value:    "123",
+									Type:     valuetype.Alphanumeric,
+								},
+							},
+						},
+					},
+				},
+			},
+			want:    `WHERE is_active = TRUE AND deleted_at IS NULL OR archived_at IS NOT NULL AND code = '123'`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - attribute reference",
+			args: args{
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Attribute: &types.Attribute{
+									Name:     "shipped_at",
+									Operator: ">",
+									Value:    "ordered_at",
+									Type:     valuetype.Reference,
+								},
+							},
+							{
+								Operator: "AND",
+								Attribute: &types.Attribute{
+									Name:     "used_quota",
+									Operator: "<",
+									Value:    "max_quota",
+									Type:     valuetype.Reference,
+								},
+							},
+						},
+					},
+				},
+			},
+			want:    `WHERE shipped_at > ordered_at AND used_quota < max_quota`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - arithmetic expression",
+			args: args{
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Attribute: &types.Attribute{
+									Name:     "price * quantity",
+									Operator: ">",
+									Value:    "1000000",
+									Type:     valuetype.Numeric,
+									Left: &types.Expression{
+										Operator: "*",
+										Left:     &types.Expression{Name: "price"},
+										Right:    &types.Expression{Name: "quantity"},
+									},
+								},
+							},
+							{
+								Operator: "AND",
+								Attribute: &types.Attribute{
+									Name:     "total",
+									Operator: ">=",
+									Value:    "(price + fee) * 2",
+									Right: &types.Expression{
+										Operator: "*",
+										Left: &types.Expression{
+											Operator: "+",
+											Left:     &types.Expression{Name: "price"},
+											Right:    &types.Expression{Name: "fee"},
+										},
+										Right: &types.Expression{Value: "2"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want:    `WHERE price * quantity > 1000000 AND total >= (price + fee) * 2`,
+			wantErr: false,
+		},
+		{
+			name: "Error case - non numeric expression literal",
+			args: args{
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Attribute: &types.Attribute{
+									Name:     "price",
+									Operator: ">",
+									Value:    "fee * 1; DROP TABLE member",
+									Right: &types.Expression{
+										Operator: "*",
+										Left:     &types.Expression{Name: "fee"},
+										Right:    &types.Expression{Value: "1; DROP TABLE member"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Normal case - function call - mysql",
+			args: args{
+				dialect: DialectMySQL,
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Attribute: &types.Attribute{
+									Name:     "year(created_at)",
+									Operator: "=",
+									Value:    "2020",
+									Type:     valuetype.Numeric,
+									Left: &types.Expression{
+										Function:  "year",
+										Arguments: []*types.Expression{{Name: "created_at"}},
+									},
+								},
+							},
+							{
+								Operator: "AND",
+								Attribute: &types.Attribute{
+									Name:     `coalesce(nickname, "O'Brien")`,
+									Operator: "=",
+									Value:    "budi",
+									Type:     valuetype.Alphanumeric,
+									Left: &types.Expression{
+										Function: "coalesce",
+										Arguments: []*types.Expression{
+											{Name: "nickname"},
+											{Value: "O'Brien", Type: valuetype.Alphanumeric},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want:    `WHERE YEAR(created_at) = 2020 AND COALESCE(nickname, 'O''Brien') = 'budi'`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - function call - postgres",
+			args: args{
+				dialect: DialectPostgres,
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Attribute: &types.Attribute{
+									Name:     "year(created_at)",
+									Operator: "=",
+									Value:    "2020",
+									Type:     valuetype.Numeric,
+									Left: &types.Expression{
+										Function:  "year",
+										Arguments: []*types.Expression{{Name: "created_at"}},
+									},
+								},
+							},
+							{
+								Operator: "AND",
+								Attribute: &types.Attribute{
+									Name:     "email",
+									Operator: "=",
+									Value:    "lower(nickname)",
+									Right: &types.Expression{
+										Function:  "lower",
+										Arguments: []*types.Expression{{Name: "nickname"}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want:    `WHERE EXTRACT(YEAR FROM created_at) = 2020 AND email = LOWER(nickname)`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - range",
+			args: args{
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Attribute: &types.Attribute{
+									Name:     "price",
+									Operator: "BETWEEN",
+									Value:    "100,500.5",
+									Type:     valuetype.Numeric,
+								},
+							},
+							{
+								Operator: "AND",
+								Attribute: &types.Attribute{
+									Name:     "created_at",
+									Operator: "NOT BETWEEN",
+									Value:    "2019-08-08 00:00:00,2019-09-09 00:00:00",
+									Type:     valuetype.Date,
+								},
+							},
+						},
+					},
+				},
+			},
+			want:    `WHERE price BETWEEN 100 AND 500.5 AND created_at NOT BETWEEN '2019-08-08 00:00:00' AND '2019-09-09 00:00:00'`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - quantifiers",
+			args: args{
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Quantifier: &types.Quantifier{
+									Operator: "any",
+									Name:     "items",
+									Condition: &types.Condition{
+										Conditions: []*types.Condition{
+											{
+												Attribute: &types.Attribute{
+													Name:     "sku",
+													Operator: "=",
+													Value:    "A1",
+													Type:     valuetype.Alphanumeric,
+												},
+											},
+											{
+												Operator: "AND",
+												Attribute: &types.Attribute{
+													Name:     "qty",
+													Operator: ">",
+													Value:    "2",
+													Type:     valuetype.Numeric,
+												},
+											},
+										},
+									},
+								},
+							},
+							{
+								Operator: "OR",
+								Negate:   true,
+								Quantifier: &types.Quantifier{
+									Operator: "all",
+									Name:     "items",
+									Condition: &types.Condition{
+										Conditions: []*types.Condition{
+											{
+												Quantifier: &types.Quantifier{
+													Operator: "none",
+													Name:     "tags",
+													Condition: &types.Condition{
+														Conditions: []*types.Condition{
+															{
+																Attribute: &types.Attribute{
+																	Name:     "tags",
+																	Operator: "=",
+																	Value:    "spam",
+																	Type:     valuetype.Alphanumeric,
+																},
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				dialect: DialectPostgres,
+			},
+			want: `WHERE EXISTS (SELECT 1 FROM jsonb_array_elements(items) AS element1(value) WHERE ( element1.value->>'sku' = 'A1' AND (element1.value->>'qty')::numeric > 2 ))
+				OR NOT (NOT EXISTS (SELECT 1 FROM jsonb_array_elements(items) AS element1(value) WHERE (NOT EXISTS (SELECT 1 FROM jsonb_array_elements(element1.value->'tags') AS element2(value) WHERE element2.value #>> '{}' = 'spam')) IS NOT TRUE))`,
+			wantErr: false,
+		},
+		{
+			name: "Error case - quantifier without dialect support",
+			args: args{
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Quantifier: &types.Quantifier{
+									Operator: "any",
+									Name:     "items",
+									Condition: &types.Condition{
+										Conditions: []*types.Condition{
+											{
+												Attribute: &types.Attribute{
+													Name:     "sku",
+													Operator: "=",
+													Value:    "A1",
+													Type:     valuetype.Alphanumeric,
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				dialect: DialectMySQL,
+			},
+			want:    ``,
+			wantErr: true,
+		},
+		{
+			name: "Normal case - aggregates",
+			args: args{
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Attribute: &types.Attribute{
+									Name:     "sum(items.price)",
+									Operator: ">",
+									Value:    "500000",
+									Type:     valuetype.Numeric,
+									Left:     &types.Expression{Aggregate: "sum", Name: "items.price"},
+								},
+							},
+							{
+								Operator: "AND",
+								Attribute: &types.Attribute{
+									Name:     "count(items)",
+									Operator: ">=",
+									Value:    "3",
+									Type:     valuetype.Numeric,
+									Left:     &types.Expression{Aggregate: "count", Name: "items"},
+								},
+							},
+						},
+					},
+				},
+				dialect: DialectPostgres,
+			},
+			want: `WHERE (SELECT COALESCE(SUM((element1.value->>'price')::numeric), 0) FROM jsonb_array_elements(items) AS element1(value)) > 500000
+				AND (SELECT COUNT(element1.value #>> '{}') FROM jsonb_array_elements(items) AS element1(value)) >= 3`,
+			wantErr: false,
+		},
+		{
+			name: "Error case - aggregate without dialect support",
+			args: args{
+				condition: []*types.Condition{
+					{
+						Conditions: []*types.Condition{
+							{
+								Attribute: &types.Attribute{
+									Name:     "avg(ratings.score)",
+									Operator: "<",
+									Value:    "2.5",
+									Type:     valuetype.Numeric,
+									Left:     &types.Expression{Aggregate: "avg", Name: "ratings.score"},
+								},
+							},
+						},
+					},
+				},
+			},
+			want:    ``,
+			wantErr: true,
+		},
 	}
 	var rgx = regexp.MustCompile(`[\s]{2,}`)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := generateWhereParameter(tt.args.condition)
+			g := QueryGen{Dialect: tt.args.dialect}
+			got, err := g.generateWhereParameter(tt.args.condition)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("generateWhereParameter() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -432,3 +1054,439 @@ func Test_generateWhereParameter(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryGen_GenerateQueryWithArgs(t *testing.T) {
+	type args struct {
+		dialect Dialect
+		params  map[string]interface{}
+	}
+	condition := types.BaseCondition{
+		Conditions: []*types.Condition{
+			{
+				Conditions: []*types.Condition{
+					{
+						Attribute: &types.Attribute{
+							Name:     "member_id",
+							Operator: "=",
+							Value:    "member_id",
+							Type:     valuetype.Parameter,
+						},
+					},
+					{
+						Operator: "AND",
+						Attribute: &types.Attribute{
+							Name:     "status",
+							Operator: "IN",
+							Value:    "statuses",
+							Type:     valuetype.Parameter,
+						},
+					},
+					{
+						Operator: "AND",
+						Attribute: &types.Attribute{
+							Name:     "name",
+							Operator: "STARTS WITH",
+							Value:    "prefix",
+							Type:     valuetype.Parameter,
+						},
+					},
+					{
+						Operator: "AND",
+						Attribute: &types.Attribute{
+							Name:     "deleted_at",
+							Operator: "=",
+							Value:    "deleted_at",
+							Type:     valuetype.Parameter,
+						},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name     string
+		args     args
+		want     string
+		wantArgs []interface{}
+		wantErr  bool
+	}{
+		{
+			name: "Normal case - postgres",
+			args: args{
+				dialect: DialectPostgres,
+				params: map[string]interface{}{
+					"member_id":  45,
+					"statuses":   []string{"active", "pending"},
+					"prefix":     "PRM_",
+					"deleted_at": nil,
+				},
+			},
+			want:     `SELECT * FROM member WHERE member_id = $1 AND status IN ($2,$3) AND name LIKE $4 AND deleted_at IS NULL`,
+			wantArgs: []interface{}{45, "active", "pending", `PRM\_%`},
+			wantErr:  false,
+		},
+		{
+			name: "Normal case - mysql",
+			args: args{
+				dialect: DialectMySQL,
+				params: map[string]interface{}{
+					"member_id":  45,
+					"statuses":   "active",
+					"prefix":     "PRM",
+					"deleted_at": "2020-01-01 00:00:00",
+				},
+			},
+			want:     `SELECT * FROM member WHERE member_id = ? AND status IN (?) AND name LIKE ? AND deleted_at = ?`,
+			wantArgs: []interface{}{45, "active", "PRM%", "2020-01-01 00:00:00"},
+			wantErr:  false,
+		},
+		{
+			name: "Error case - unbound parameter",
+			args: args{
+				dialect: DialectMySQL,
+				params: map[string]interface{}{
+					"member_id": 45,
+				},
+			},
+			wantErr: true,
+		},
+	}
+	var rgx = regexp.MustCompile(`[\s]{2,}`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := QueryGen{Dialect: tt.args.dialect}
+			got, gotArgs, err := g.GenerateQueryWithArgs("SELECT * FROM member", condition, tt.args.params)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GenerateQueryWithArgs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			strGot := strings.TrimSpace(rgx.ReplaceAllString(got, " "))
+			strWant := strings.TrimSpace(rgx.ReplaceAllString(tt.want, " "))
+
+			if strGot != strWant {
+				t.Errorf("GenerateQueryWithArgs() got = %v, want %v", strGot, strWant)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("GenerateQueryWithArgs() gotArgs = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestQueryGen_GenerateQueryWithArgsConcurrent(t *testing.T) {
+	condition := types.BaseCondition{
+		Conditions: []*types.Condition{
+			{
+				Conditions: []*types.Condition{
+					{
+						Attribute: &types.Attribute{
+							Name:     "member_id",
+							Operator: "=",
+							Value:    "member_id",
+							Type:     valuetype.Parameter,
+						},
+					},
+					{
+						Operator: "AND",
+						Attribute: &types.Attribute{
+							Name:     "status",
+							Operator: "IN",
+							Value:    "statuses",
+							Type:     valuetype.Parameter,
+						},
+					},
+				},
+			},
+		},
+	}
+	g := QueryGen{Dialect: DialectPostgres}
+	errs := make(chan error, 16)
+	for i := 0; i < cap(errs); i++ {
+		go func(memberID int) {
+			params := map[string]interface{}{
+				"member_id": memberID,
+				"statuses":  []string{"active", "pending"},
+			}
+			_, gotArgs, err := g.GenerateQueryWithArgs("SELECT * FROM member", condition, params)
+			if err == nil && !reflect.DeepEqual(gotArgs, []interface{}{memberID, "active", "pending"}) {
+				err = fmt.Errorf("GenerateQueryWithArgs() gotArgs = %v for member_id %d", gotArgs, memberID)
+			}
+			errs <- err
+		}(i)
+	}
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestQueryGen_GenerateQueryRegistry(t *testing.T) {
+	r := registry.New()
+	for _, function := range []registry.Function{
+		{
+			Name:       "is_weekend",
+			Arguments:  []valuetype.ValueType{valuetype.Date},
+			ReturnType: valuetype.Boolean,
+			Call: func(arguments ...interface{}) (interface{}, error) {
+				return nil, nil
+			},
+			SQL: "EXTRACT(DOW FROM %[1]s) IN (0, 6)",
+		},
+		{
+			Name:       "sku_in_category",
+			Arguments:  []valuetype.ValueType{valuetype.Alphanumeric, valuetype.Alphanumeric},
+			ReturnType: valuetype.Boolean,
+			Call: func(arguments ...interface{}) (interface{}, error) {
+				return nil, nil
+			},
+		},
+	} {
+		if err := r.RegisterFunction(function); err != nil {
+			t.Fatalf("RegisterFunction() error = %v", err)
+		}
+	}
+	for _, operator := range []registry.Operator{
+		{
+			Name: "HAS",
+			Call: func(left, right interface{}) (bool, error) {
+				return false, nil
+			},
+			SQL: "%[2]s = ANY(%[1]s)",
+		},
+		{
+			Name: "NEAR",
+			Call: func(left, right interface{}) (bool, error) {
+				return false, nil
+			},
+		},
+	} {
+		if err := r.RegisterOperator(operator); err != nil {
+			t.Fatalf("RegisterOperator() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		condition []*types.Condition
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "Normal case",
+			condition: []*types.Condition{
+				{
+					Attribute: &types.Attribute{
+						Name:     "is_weekend(created_at)",
+						Operator: "=",
+						Value:    "true",
+						Type:     valuetype.Boolean,
+						Left: &types.Expression{
+							Function:  "is_weekend",
+							Arguments: []*types.Expression{{Name: "created_at"}},
+						},
+					},
+				},
+				{
+					Operator: "AND",
+					Attribute: &types.Attribute{
+						Name:     "tags",
+						Operator: "HAS",
+						Value:    "vip",
+						Type:     valuetype.Alphanumeric,
+					},
+				},
+			},
+			want:    `SELECT * FROM member WHERE EXTRACT(DOW FROM created_at) IN (0, 6) = TRUE AND 'vip' = ANY(tags)`,
+			wantErr: false,
+		},
+		{
+			name: "Error case - function without SQL template",
+			condition: []*types.Condition{
+				{
+					Attribute: &types.Attribute{
+						Name:     "sku_in_category(sku, \"shoes\")",
+						Operator: "=",
+						Value:    "true",
+						Type:     valuetype.Boolean,
+						Left: &types.Expression{
+							Function: "sku_in_category",
+							Arguments: []*types.Expression{
+								{Name: "sku"},
+								{Value: "shoes", Type: valuetype.Alphanumeric},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error case - operator without SQL template",
+			condition: []*types.Condition{
+				{
+					Attribute: &types.Attribute{
+						Name:     "location",
+						Operator: "NEAR",
+						Value:    "office",
+						Type:     valuetype.Alphanumeric,
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	var rgx = regexp.MustCompile(`[\s]{2,}`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := QueryGen{Dialect: DialectPostgres, Registry: r}
+			got, err := g.GenerateQuery("SELECT * FROM member", types.BaseCondition{
+				Conditions: []*types.Condition{{Conditions: tt.condition}},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GenerateQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			strGot := strings.TrimSpace(rgx.ReplaceAllString(got, " "))
+			strWant := strings.TrimSpace(rgx.ReplaceAllString(tt.want, " "))
+
+			if strGot != strWant {
+				t.Errorf("GenerateQuery() got = %v, want %v", strGot, strWant)
+			}
+		})
+	}
+}
+
+func TestQueryGen_GenerateQueryRelativeDate(t *testing.T) {
+	type args struct {
+		dialect  Dialect
+		now      func() time.Time
+		location *time.Location
+		isBound  bool
+	}
+	condition := types.BaseCondition{
+		Conditions: []*types.Condition{
+			{
+				Conditions: []*types.Condition{
+					{
+						Attribute: &types.Attribute{
+							Name:     "registered_at",
+							Operator: ">=",
+							Value:    "now() - 2w",
+							Right: &types.Expression{
+								Operator: "-",
+								Left:     &types.Expression{Function: "now"},
+								Right:    &types.Expression{Value: "2w", Type: valuetype.Duration},
+							},
+						},
+					},
+					{
+						Operator: "AND",
+						Attribute: &types.Attribute{
+							Name:     "created_at",
+							Operator: "<",
+							Value:    "startofmonth()",
+							Right:    &types.Expression{Function: "startofmonth"},
+						},
+					},
+				},
+			},
+		},
+	}
+	now := func() time.Time {
+		return time.Date(2020, 3, 18, 10, 30, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name     string
+		args     args
+		want     string
+		wantArgs []interface{}
+		wantErr  bool
+	}{
+		{
+			name: "Normal case - postgres",
+			args: args{
+				dialect: DialectPostgres,
+			},
+			want:    `SELECT * FROM member WHERE registered_at >= NOW() - INTERVAL '14 day' AND created_at < DATE_TRUNC('month', CURRENT_DATE)`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - mysql",
+			args: args{
+				dialect: DialectMySQL,
+			},
+			want:    `SELECT * FROM member WHERE registered_at >= NOW() - INTERVAL 14 DAY AND created_at < DATE_SUB(CURDATE(), INTERVAL DAYOFMONTH(CURDATE()) - 1 DAY)`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - standard sql",
+			args: args{},
+			want: `SELECT * FROM member WHERE registered_at >= CURRENT_TIMESTAMP - INTERVAL '14' DAY ` +
+				`AND created_at < CURRENT_DATE - (EXTRACT(DAY FROM CURRENT_DATE) - 1) * INTERVAL '1' DAY`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - resolved timestamps",
+			args: args{
+				dialect: DialectMySQL,
+				now:     now,
+			},
+			want:    `SELECT * FROM member WHERE registered_at >= '2020-03-04 10:30:00' AND created_at < '2020-03-01 00:00:00'`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - resolved timestamps in location",
+			args: args{
+				dialect:  DialectMySQL,
+				now:      now,
+				location: time.FixedZone("Asia/Jakarta", 7*60*60),
+			},
+			want:    `SELECT * FROM member WHERE registered_at >= '2020-03-04 17:30:00' AND created_at < '2020-03-01 07:00:00'`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - bound timestamps",
+			args: args{
+				dialect: DialectPostgres,
+				now:     now,
+				isBound: true,
+			},
+			want: `SELECT * FROM member WHERE registered_at >= $1 AND created_at < $2`,
+			wantArgs: []interface{}{
+				time.Date(2020, 3, 4, 10, 30, 0, 0, time.UTC),
+				time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: false,
+		},
+	}
+	var rgx = regexp.MustCompile(`[\s]{2,}`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got     string
+				gotArgs []interface{}
+				err     error
+			)
+			g := QueryGen{Dialect: tt.args.dialect, Now: tt.args.now, Location: tt.args.location}
+			if tt.args.isBound {
+				got, gotArgs, err = g.GenerateQueryWithArgs("SELECT * FROM member", condition, nil)
+			} else {
+				got, err = g.GenerateQuery("SELECT * FROM member", condition)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GenerateQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			strGot := strings.TrimSpace(rgx.ReplaceAllString(got, " "))
+			strWant := strings.TrimSpace(rgx.ReplaceAllString(tt.want, " "))
+
+			if strGot != strWant {
+				t.Errorf("GenerateQuery() got = %v, want %v", strGot, strWant)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("GenerateQuery() gotArgs = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
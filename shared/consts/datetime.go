@@ -1,3 +1,18 @@
 package consts
 
-const DateTimeFormat = "2006-01-02 15:04:05"
+const (
+	DateTimeFormat = "2006-01-02 15:04:05"
+	DateFormat     = "2006-01-02"
+)
+
+// EpochMillisecondThreshold is the smallest Unix epoch read as milliseconds
+// rather than seconds, seconds that large are more than 30000 years away.
+const EpochMillisecondThreshold = 1e12
+
+const (
+	DurationUnitSecond = "s"
+	DurationUnitMinute = "m"
+	DurationUnitHour   = "h"
+	DurationUnitDay    = "d"
+	DurationUnitWeek   = "w"
+)
@@ -1,8 +1,62 @@
 package consts
 
 const (
-	ErrorMessageInvalidData        = "data can't be %s"
-	ErrorMessageInvalidParameter   = "invalid parameter, %s is required"
-	ErrorMessageInvalidType        = "invalid type, %s is required"
-	ErrorMessageUnableToCastObject = "unable to cast object"
+	ErrorMessageInvalidData         = "data can't be %s"
+	ErrorMessageInvalidParameter    = "invalid parameter, %s is required"
+	ErrorMessageInvalidType         = "invalid type, %s is required"
+	ErrorMessageUnableToCastObject  = "unable to cast object"
+	ErrorMessageUnsupportedOperator = "operator %s is not supported by the %q dialect"
+	ErrorMessageUnboundParameter    = "unbound parameter %s"
+	ErrorMessageInvalidBindValue    = "invalid value %v for parameter %s"
+
+	ErrorMessageInvalidName           = "invalid name %q"
+	ErrorMessageReservedName          = "name %q is reserved"
+	ErrorMessageAlreadyRegistered     = "name %q is already registered"
+	ErrorMessageMissingImplementation = "missing implementation of %q"
+	ErrorMessageMissingSQLTemplate    = "%s has no SQL template"
+	ErrorMessageInvalidArgument       = "invalid argument %v for %s"
+	ErrorMessageInvalidSymbol         = "invalid %s symbol %q"
+	ErrorMessageDuplicateSymbol       = "symbol %q is already used"
+	ErrorMessageUnknownMapping        = "symbol %q maps to unknown operator %q"
+
+	ErrorMessageInvalidCollection = "%s(%s) requires a slice or an array, %s is %s"
+	ErrorMessageInvalidAggregate  = "%s(%s) requires numeric values, got %v"
+	ErrorMessageInvalidCondition  = "invalid condition %s%s: %w"
+	ErrorMessageLimitExceeded     = "%s exceeds the limit of %d"
+
+	ErrorMessageSyntax                  = "syntax error at line %d, column %d near %q: %s"
+	ErrorMessageExpectedAttribute       = "expected attribute name"
+	ErrorMessageExpectedLogicalOperator = "expected logical operator"
+	ErrorMessageUnknownOperator         = "unknown operator"
+	ErrorMessageForeignOperator         = "operator %s is not part of the dialect, use %s"
+	ErrorMessageMissingOperator         = "missing operator after attribute"
+	ErrorMessageMissingValue            = "missing value after operator"
+	ErrorMessageExpectedList            = "expected parenthesized list of values"
+	ErrorMessageExpectedListSeparator   = "expected comma or closing parenthesis"
+	ErrorMessageExpectedRangeSeparator  = "expected AND between range bounds"
+	ErrorMessageInvalidRange            = "range bounds must both be numbers or dates"
+	ErrorMessageMissingCondition        = "missing condition after logical operator"
+	ErrorMessageEmptyGroup              = "empty parenthesis group"
+	ErrorMessageUnclosedParenthesis     = "unclosed parenthesis"
+	ErrorMessageUnbalancedParenthesis   = "unbalanced closing parenthesis"
+	ErrorMessageInvalidRegex            = "invalid regular expression: %s"
+	ErrorMessageUnquotedValue           = "value with whitespace must be quoted"
+	ErrorMessageUnterminatedQuote       = "unterminated quoted value"
+	ErrorMessageUnterminatedComment     = "unterminated block comment"
+	ErrorMessageInvalidNullComparison   = "null can only be compared with = or !="
+	ErrorMessageInvalidReference        = "field reference can only be compared with =, !=, <, <=, > or >="
+	ErrorMessageInvalidExpression       = "arithmetic expression can only be compared with =, !=, <, <=, > or >="
+	ErrorMessageMissingOperand          = "missing operand after arithmetic operator"
+	ErrorMessageInvalidOperand          = "arithmetic operand must be an attribute, a number or a duration"
+	ErrorMessageInvalidDateArithmetic   = "dates and durations can only be added or subtracted"
+	ErrorMessageUnknownFunction         = "unknown function"
+	ErrorMessageMissingArgument         = "missing function argument"
+	ErrorMessageFunctionArguments       = "wrong number of arguments for function %s"
+	ErrorMessageInvalidArgumentType     = "argument %d of function %s must be %s"
+	ErrorMessageExpectedQuantifier      = "expected comma and condition after quantified attribute"
+	ErrorMessageExpectedRuleName        = "expected rule name followed by a colon"
+	ErrorMessageDuplicateRuleName       = "duplicate rule name"
+	ErrorMessageEmptyRule               = "missing condition after rule name"
+	ErrorMessageUndefinedCondition      = "undefined condition"
+	ErrorMessageCyclicCondition         = "cyclic condition reference %s"
 )
@@ -0,0 +1,25 @@
+package consts
+
+const (
+	FunctionLower    = "lower"
+	FunctionUpper    = "upper"
+	FunctionTrim     = "trim"
+	FunctionLen      = "len"
+	FunctionAbs      = "abs"
+	FunctionYear     = "year"
+	FunctionMonth    = "month"
+	FunctionDay      = "day"
+	FunctionCoalesce = "coalesce"
+
+	FunctionNow          = "now"
+	FunctionToday        = "today"
+	FunctionStartOfMonth = "startofmonth"
+)
+
+const (
+	AggregateCount = "count"
+	AggregateSum   = "sum"
+	AggregateAvg   = "avg"
+	AggregateMin   = "min"
+	AggregateMax   = "max"
+)
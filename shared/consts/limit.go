@@ -0,0 +1,8 @@
+package consts
+
+const (
+	LimitDepth       = "nesting depth"
+	LimitTokens      = "token count"
+	LimitListSize    = "list size"
+	LimitQueryLength = "query length"
+)
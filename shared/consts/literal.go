@@ -0,0 +1,11 @@
+package consts
+
+const (
+	LiteralTrue  = "true"
+	LiteralFalse = "false"
+	LiteralNull  = "null"
+
+	ReferencePrefix = "$"
+	ParameterPrefix = ":"
+	ConditionPrefix = "@"
+)
@@ -3,9 +3,11 @@ package consts
 const (
 	LogicalOperatorAnd = "AND"
 	LogicalOperatorOr  = "OR"
+	LogicalOperatorNot = "NOT"
 
 	LogicalOperatorAndSyntax = "&&"
 	LogicalOperatorOrSyntax  = "||"
+	LogicalOperatorNotSyntax = "!"
 )
 
 const (
@@ -17,7 +19,28 @@ const (
 	OperatorGreaterThanEqual = ">="
 	OperatorInclude          = "IN"
 	OperatorExclude          = "NOT IN"
+	OperatorBetween          = "BETWEEN"
+	OperatorNotBetween       = "NOT BETWEEN"
 	OperatorIsNull           = "IS NULL"
 	OperatorIsNotNull        = "IS NOT NULL"
 	OperatorLike             = "LIKE"
+	OperatorStartsWith       = "STARTS WITH"
+	OperatorEndsWith         = "ENDS WITH"
+	OperatorContains         = "CONTAINS"
+	OperatorRegex            = "~"
+	OperatorRegexMySQL       = "REGEXP"
+)
+
+const (
+	QuantifierAny  = "any"
+	QuantifierAll  = "all"
+	QuantifierNone = "none"
+)
+
+const (
+	ArithmeticOperatorAdd      = "+"
+	ArithmeticOperatorSubtract = "-"
+	ArithmeticOperatorMultiply = "*"
+	ArithmeticOperatorDivide   = "/"
+	ArithmeticOperatorModulo   = "%"
 )
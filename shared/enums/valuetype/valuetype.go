@@ -6,6 +6,11 @@ const (
 	Numeric      ValueType = "numeric"
 	Alphanumeric ValueType = "alphanumeric"
 	Date         ValueType = "date"
+	Boolean      ValueType = "boolean"
+	Null         ValueType = "null"
+	Reference    ValueType = "reference"
+	Parameter    ValueType = "parameter"
+	Duration     ValueType = "duration"
 )
 
 func FromString(value string) ValueType {
@@ -0,0 +1,224 @@
+package registry
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Function is a custom function callable from conditions, e.g.
+// is_weekend(order_date). Its arguments are converted to the declared types
+// before Call, an empty type accepts the value as it is. A function returning a
+// boolean can be used as a condition on its own.
+type Function struct {
+	Name       string
+	Arguments  []valuetype.ValueType
+	Variadic   bool
+	ReturnType valuetype.ValueType
+	Call       func(arguments ...interface{}) (interface{}, error)
+	// SQL is the template used by querygen, where %[n]s is replaced by the
+	// nth generated argument. A function without it can't be generated.
+	SQL string
+}
+
+// Operator is a custom binary operator made of one or more words, e.g.
+// tags HAS "vip". The attribute value and the compared value are converted to
+// LeftType and RightType before Call.
+type Operator struct {
+	Name      string
+	LeftType  valuetype.ValueType
+	RightType valuetype.ValueType
+	Call      func(left, right interface{}) (bool, error)
+	// SQL is the template used by querygen, where %[1]s is replaced by the
+	// attribute and %[2]s by the compared value.
+	SQL string
+}
+
+// Registry holds the custom functions and operators known to the parser, the
+// validator and the query generator, and the named conditions the parser
+// expands.
+type Registry struct {
+	mutex      sync.RWMutex
+	functions  map[string]*Function
+	operators  map[string]*Operator
+	conditions map[string]string
+}
+
+// Default is the registry used by components that aren't given one.
+var Default = New()
+
+var (
+	functionNameRegex  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	operatorNameRegex  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*( [A-Za-z_][A-Za-z0-9_]*){0,2}$`)
+	conditionNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+	reservedFunctionMap = map[string]interface{}{
+		consts.FunctionLower:        nil,
+		consts.FunctionUpper:        nil,
+		consts.FunctionTrim:         nil,
+		consts.FunctionLen:          nil,
+		consts.FunctionAbs:          nil,
+		consts.FunctionYear:         nil,
+		consts.FunctionMonth:        nil,
+		consts.FunctionDay:          nil,
+		consts.FunctionCoalesce:     nil,
+		consts.FunctionNow:          nil,
+		consts.FunctionToday:        nil,
+		consts.FunctionStartOfMonth: nil,
+		consts.AggregateCount:       nil,
+		consts.AggregateSum:         nil,
+		consts.AggregateAvg:         nil,
+		consts.AggregateMin:         nil,
+		consts.AggregateMax:         nil,
+		consts.QuantifierAny:        nil,
+		consts.QuantifierAll:        nil,
+		consts.QuantifierNone:       nil,
+		consts.LiteralTrue:          nil,
+		consts.LiteralFalse:         nil,
+		consts.LiteralNull:          nil,
+	}
+
+	reservedOperatorMap = map[string]interface{}{
+		consts.OperatorInclude:    nil,
+		consts.OperatorExclude:    nil,
+		consts.OperatorBetween:    nil,
+		consts.OperatorNotBetween: nil,
+		consts.OperatorIsNull:     nil,
+		consts.OperatorIsNotNull:  nil,
+		consts.OperatorLike:       nil,
+		consts.OperatorStartsWith: nil,
+		consts.OperatorEndsWith:   nil,
+		consts.OperatorContains:   nil,
+		consts.OperatorRegexMySQL: nil,
+		consts.LogicalOperatorAnd: nil,
+		consts.LogicalOperatorOr:  nil,
+		consts.LogicalOperatorNot: nil,
+	}
+)
+
+func New() *Registry {
+	return &Registry{
+		functions:  make(map[string]*Function),
+		operators:  make(map[string]*Operator),
+		conditions: make(map[string]string),
+	}
+}
+
+// RegisterFunction adds function to the registry. Function names are case
+// insensitive and can't replace a built-in or an already registered function.
+func (r *Registry) RegisterFunction(function Function) error {
+	name := strings.ToLower(function.Name)
+	if !functionNameRegex.MatchString(name) {
+		return fmt.Errorf(consts.ErrorMessageInvalidName, function.Name)
+	}
+	if _, ok := reservedFunctionMap[name]; ok {
+		return fmt.Errorf(consts.ErrorMessageReservedName, function.Name)
+	}
+	if function.Call == nil {
+		return fmt.Errorf(consts.ErrorMessageMissingImplementation, function.Name)
+	}
+	if function.Variadic && len(function.Arguments) == 0 {
+		return fmt.Errorf(consts.ErrorMessageInvalidParameter, "variadic argument type")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.functions[name]; ok {
+		return fmt.Errorf(consts.ErrorMessageAlreadyRegistered, function.Name)
+	}
+	function.Name = name
+	function.Arguments = append([]valuetype.ValueType(nil), function.Arguments...)
+	r.functions[name] = &function
+	return nil
+}
+
+// RegisterOperator adds operator to the registry. Operator names are words
+// separated by single spaces, matched as written, and can't replace a built-in
+// or an already registered operator.
+func (r *Registry) RegisterOperator(operator Operator) error {
+	if !operatorNameRegex.MatchString(operator.Name) {
+		return fmt.Errorf(consts.ErrorMessageInvalidName, operator.Name)
+	}
+	if _, ok := reservedOperatorMap[strings.ToUpper(operator.Name)]; ok {
+		return fmt.Errorf(consts.ErrorMessageReservedName, operator.Name)
+	}
+	if operator.Call == nil {
+		return fmt.Errorf(consts.ErrorMessageMissingImplementation, operator.Name)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.operators[operator.Name]; ok {
+		return fmt.Errorf(consts.ErrorMessageAlreadyRegistered, operator.Name)
+	}
+	r.operators[operator.Name] = &operator
+	return nil
+}
+
+// RegisterCondition adds a named condition written in the condition syntax,
+// referenced as @name by other conditions and expanded when they're parsed.
+// Names are case sensitive, and query is only parsed when it's referenced, so
+// it can reference conditions registered later.
+func (r *Registry) RegisterCondition(name, query string) error {
+	if !conditionNameRegex.MatchString(name) {
+		return fmt.Errorf(consts.ErrorMessageInvalidName, name)
+	}
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf(consts.ErrorMessageInvalidParameter, "query")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.conditions[name]; ok {
+		return fmt.Errorf(consts.ErrorMessageAlreadyRegistered, name)
+	}
+	r.conditions[name] = query
+	return nil
+}
+
+// Function returns the function registered as name, case insensitively.
+func (r *Registry) Function(name string) (*Function, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	function, ok := r.functions[strings.ToLower(name)]
+	return function, ok
+}
+
+// Operator returns the operator registered as name.
+func (r *Registry) Operator(name string) (*Operator, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	operator, ok := r.operators[name]
+	return operator, ok
+}
+
+// Condition returns the query of the condition registered as name.
+func (r *Registry) Condition(name string) (string, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	query, ok := r.conditions[name]
+	return query, ok
+}
+
+// ArgumentType returns the declared type of the argument at index, repeating
+// the last one for variadic functions.
+func (f *Function) ArgumentType(index int) valuetype.ValueType {
+	if index >= len(f.Arguments) {
+		if !f.Variadic {
+			return ""
+		}
+		index = len(f.Arguments) - 1
+	}
+	return f.Arguments[index]
+}
+
+// IsValidArity reports whether the function accepts count arguments.
+func (f *Function) IsValidArity(count int) bool {
+	if f.Variadic {
+		return count >= len(f.Arguments)
+	}
+	return count == len(f.Arguments)
+}
@@ -7,10 +7,30 @@ type Attribute struct {
 	Operator string              `json:"operator"`
 	Value    string              `json:"value"`
 	Type     valuetype.ValueType `json:"type,omitempty"`
+	Left     *Expression         `json:"left,omitempty"`
+	Right    *Expression         `json:"right,omitempty"`
+}
+
+// Expression is an arithmetic expression or a function call compared by an
+// attribute. A leaf is either an attribute Name or a Value, numeric unless its
+// Type says otherwise. Other nodes apply Operator to Left and Right, or call
+// Function with Arguments. An Aggregate like sum computes a value over the
+// collection Name, e.g. items.price for the price of every element of items.
+type Expression struct {
+	Operator  string              `json:"operator,omitempty"`
+	Left      *Expression         `json:"left,omitempty"`
+	Right     *Expression         `json:"right,omitempty"`
+	Function  string              `json:"function,omitempty"`
+	Arguments []*Expression       `json:"arguments,omitempty"`
+	Aggregate string              `json:"aggregate,omitempty"`
+	Name      string              `json:"name,omitempty"`
+	Value     string              `json:"value,omitempty"`
+	Type      valuetype.ValueType `json:"type,omitempty"`
 }
 
 type TokenAttribute struct {
 	Value          string
 	IsAlphanumeric bool
-	HasCalled      bool
+	Line           int
+	Column         int
 }
@@ -9,6 +9,17 @@ type BaseCondition struct {
 
 type Condition struct {
 	Operator   string       `json:"operator,omitempty"`
+	Negate     bool         `json:"negate,omitempty"`
 	Attribute  *Attribute   `json:"attribute,omitempty"`
+	Quantifier *Quantifier  `json:"quantifier,omitempty"`
 	Conditions []*Condition `json:"conditions,omitempty"`
 }
+
+// Quantifier matches the elements of the slice attribute Name against
+// Condition, e.g. any(items, qty > 2). Scalar elements are matched as an
+// attribute named Name.
+type Quantifier struct {
+	Operator  string     `json:"operator"`
+	Name      string     `json:"name"`
+	Condition *Condition `json:"condition"`
+}
@@ -0,0 +1,164 @@
+package types
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"strings"
+)
+
+// Limits bounds the size of queries and condition objects accepted from
+// untrusted input. A zero field takes its value from DefaultLimits and a
+// negative field disables the limit.
+type Limits struct {
+	// MaxDepth is the maximum nesting of groups, quantifiers and function calls.
+	MaxDepth int
+	// MaxTokens is the maximum number of tokens of a query, of conditions and
+	// expressions added by expanding its named conditions, or of conditions and
+	// expressions of a condition object.
+	MaxTokens int
+	// MaxListSize is the maximum number of values of an IN or NOT IN list.
+	MaxListSize int
+	// MaxQueryLength is the maximum length in bytes of a query, or of a name or
+	// a value of a condition object.
+	MaxQueryLength int
+}
+
+// DefaultLimits are the limits used for the fields of Limits left to zero.
+var DefaultLimits = Limits{
+	MaxDepth:       32,
+	MaxTokens:      4096,
+	MaxListSize:    1024,
+	MaxQueryLength: 65536,
+}
+
+// LimitError is returned when a query or a condition object exceeds one of its
+// limits.
+type LimitError struct {
+	Limit string
+	Max   int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf(consts.ErrorMessageLimitExceeded, e.Limit, e.Max)
+}
+
+// WithDefaults returns the limits with the zero fields set from DefaultLimits.
+func (l Limits) WithDefaults() Limits {
+	if l.MaxDepth == 0 {
+		l.MaxDepth = DefaultLimits.MaxDepth
+	}
+	if l.MaxTokens == 0 {
+		l.MaxTokens = DefaultLimits.MaxTokens
+	}
+	if l.MaxListSize == 0 {
+		l.MaxListSize = DefaultLimits.MaxListSize
+	}
+	if l.MaxQueryLength == 0 {
+		l.MaxQueryLength = DefaultLimits.MaxQueryLength
+	}
+	return l
+}
+
+// CheckLimit returns a LimitError when value exceeds max, unless max is
+// negative.
+func CheckLimit(limit string, max, value int) error {
+	if max >= 0 && value > max {
+		return &LimitError{Limit: limit, Max: max}
+	}
+	return nil
+}
+
+// CheckCondition checks a condition object, e.g. one decoded from JSON, against
+// the limits before it's validated or turned into a query. A quantifier without
+// a condition is an error too.
+func (l Limits) CheckCondition(condition *Condition) error {
+	l = l.WithDefaults()
+	count := 0
+	return l.checkCondition(condition, 0, &count)
+}
+
+func (l Limits) checkCondition(condition *Condition, depth int, count *int) error {
+	if condition == nil {
+		return nil
+	}
+	*count++
+	if err := CheckLimit(consts.LimitTokens, l.MaxTokens, *count); err != nil {
+		return err
+	}
+	if err := CheckLimit(consts.LimitDepth, l.MaxDepth, depth); err != nil {
+		return err
+	}
+	if attribute := condition.Attribute; attribute != nil {
+		if err := l.checkAttribute(attribute, depth, count); err != nil {
+			return err
+		}
+	}
+	if quantifier := condition.Quantifier; quantifier != nil {
+		if quantifier.Condition == nil {
+			return fmt.Errorf(consts.ErrorMessageInvalidParameter, "quantifier condition")
+		}
+		if err := CheckLimit(consts.LimitQueryLength, l.MaxQueryLength, len(quantifier.Name)); err != nil {
+			return err
+		}
+		if err := l.checkCondition(quantifier.Condition, depth+1, count); err != nil {
+			return err
+		}
+	}
+	for _, subCondition := range condition.Conditions {
+		if err := l.checkCondition(subCondition, depth+1, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l Limits) checkAttribute(attribute *Attribute, depth int, count *int) error {
+	if err := CheckLimit(consts.LimitQueryLength, l.MaxQueryLength, len(attribute.Name)); err != nil {
+		return err
+	}
+	if err := CheckLimit(consts.LimitQueryLength, l.MaxQueryLength, len(attribute.Value)); err != nil {
+		return err
+	}
+	switch attribute.Operator {
+	case consts.OperatorInclude, consts.OperatorExclude:
+		size := strings.Count(attribute.Value, ",") + 1
+		if err := CheckLimit(consts.LimitListSize, l.MaxListSize, size); err != nil {
+			return err
+		}
+	}
+	if err := l.checkExpression(attribute.Left, depth, count); err != nil {
+		return err
+	}
+	return l.checkExpression(attribute.Right, depth, count)
+}
+
+func (l Limits) checkExpression(expression *Expression, depth int, count *int) error {
+	if expression == nil {
+		return nil
+	}
+	*count++
+	if err := CheckLimit(consts.LimitTokens, l.MaxTokens, *count); err != nil {
+		return err
+	}
+	if err := CheckLimit(consts.LimitDepth, l.MaxDepth, depth); err != nil {
+		return err
+	}
+	if err := CheckLimit(consts.LimitQueryLength, l.MaxQueryLength, len(expression.Name)); err != nil {
+		return err
+	}
+	if err := CheckLimit(consts.LimitQueryLength, l.MaxQueryLength, len(expression.Value)); err != nil {
+		return err
+	}
+	if err := l.checkExpression(expression.Left, depth, count); err != nil {
+		return err
+	}
+	if err := l.checkExpression(expression.Right, depth, count); err != nil {
+		return err
+	}
+	for _, argument := range expression.Arguments {
+		if err := l.checkExpression(argument, depth+1, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,7 +1,6 @@
 package utils
 
 import (
-	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
 	"strconv"
 	"time"
 )
@@ -26,7 +25,7 @@ func StringToFloat64(value string) float64 {
 
 func StringToTime(value string) time.Time {
 	var timeValue time.Time
-	timeValue, err := time.Parse(consts.DateTimeFormat, value)
+	timeValue, err := ParseTime(value)
 	if err != nil {
 		return time.Time{}
 	}
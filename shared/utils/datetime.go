@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	durationRegex = regexp.MustCompile(`^([0-9]+)([smhdw])$`)
+
+	timeFormats = []string{consts.DateTimeFormat, time.RFC3339Nano, consts.DateFormat}
+
+	locationMutex   sync.RWMutex
+	defaultLocation = time.UTC
+)
+
+var durationUnitMap = map[string]time.Duration{
+	consts.DurationUnitSecond: time.Second,
+	consts.DurationUnitMinute: time.Minute,
+	consts.DurationUnitHour:   time.Hour,
+	consts.DurationUnitDay:    24 * time.Hour,
+	consts.DurationUnitWeek:   7 * 24 * time.Hour,
+}
+
+// SetDefaultLocation sets the location of times parsed without a time zone when
+// no location is given, UTC unless set.
+func SetDefaultLocation(location *time.Location) {
+	if location == nil {
+		location = time.UTC
+	}
+	locationMutex.Lock()
+	defer locationMutex.Unlock()
+	defaultLocation = location
+}
+
+// DefaultLocation returns the location set by SetDefaultLocation, used when no
+// location is given to ParseTimeIn, EpochToTimeIn and FormatTimeIn.
+func DefaultLocation() *time.Location {
+	locationMutex.RLock()
+	defer locationMutex.RUnlock()
+	return defaultLocation
+}
+
+// ParseTime parses value in the date time, RFC 3339 or date only format. Values
+// without a time zone are in the default location. Unix epochs aren't parsed,
+// as they can't be told from numbers, see EpochToTime.
+func ParseTime(value string) (time.Time, error) {
+	return ParseTimeIn(value, nil)
+}
+
+// ParseTimeIn parses value like ParseTime, values without a time zone are in
+// location, the default location when nil.
+func ParseTimeIn(value string, location *time.Location) (time.Time, error) {
+	if location == nil {
+		location = DefaultLocation()
+	}
+	var err error
+	for _, format := range timeFormats {
+		var timeValue time.Time
+		if timeValue, err = time.ParseInLocation(format, value, location); err == nil {
+			return timeValue, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// EpochToTime converts Unix epoch seconds, or milliseconds from
+// consts.EpochMillisecondThreshold, to a time in the default location.
+func EpochToTime(value int64) time.Time {
+	return EpochToTimeIn(value, nil)
+}
+
+// EpochToTimeIn converts an epoch like EpochToTime, to a time in location, the
+// default location when nil.
+func EpochToTimeIn(value int64, location *time.Location) time.Time {
+	if location == nil {
+		location = DefaultLocation()
+	}
+	if value >= consts.EpochMillisecondThreshold || value <= -consts.EpochMillisecondThreshold {
+		return time.UnixMilli(value).In(location)
+	}
+	return time.Unix(value, 0).In(location)
+}
+
+// FormatTime formats value in the date time format, in the default location.
+func FormatTime(value time.Time) string {
+	return FormatTimeIn(value, nil)
+}
+
+// FormatTimeIn formats value in the date time format, in location, the default
+// location when nil.
+func FormatTimeIn(value time.Time, location *time.Location) string {
+	if location == nil {
+		location = DefaultLocation()
+	}
+	return value.In(location).Format(consts.DateTimeFormat)
+}
+
+// SplitDuration splits a duration literal like 7d into its amount and unit.
+func SplitDuration(value string) (int64, string, bool) {
+	matches := durationRegex.FindStringSubmatch(value)
+	if matches == nil {
+		return 0, "", false
+	}
+	amount, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return amount, matches[2], true
+}
+
+// StringToDuration converts a duration literal like 7d to time.Duration.
+func StringToDuration(value string) (time.Duration, bool) {
+	amount, unit, ok := SplitDuration(value)
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(amount) * durationUnitMap[unit], true
+}
+
+// StartOfDay returns midnight of the day of value, in its location.
+func StartOfDay(value time.Time) time.Time {
+	year, month, day := value.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, value.Location())
+}
+
+// StartOfMonth returns midnight of the first day of the month of value, in its
+// location.
+func StartOfMonth(value time.Time) time.Time {
+	year, month, _ := value.Date()
+	return time.Date(year, month, 1, 0, 0, 0, 0, value.Location())
+}
@@ -0,0 +1,114 @@
+package structgen
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/utils"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BindCondition returns a copy of condition with its parameters replaced by
+// the values in params. Parameters missing from params are reported as errors,
+// bound times are written in Location.
+func (s *StructGen) BindCondition(condition types.Condition, params map[string]interface{}) (types.Condition, error) {
+	if condition.Attribute != nil {
+		attribute := *condition.Attribute
+		if attribute.Type == valuetype.Parameter {
+			param, ok := params[attribute.Value]
+			if !ok {
+				return types.Condition{}, fmt.Errorf(consts.ErrorMessageUnboundParameter, attribute.Value)
+			}
+			value, valueType, ok := getParameterLiteral(param, attribute.Operator, s.Location)
+			if !ok {
+				return types.Condition{}, fmt.Errorf(consts.ErrorMessageInvalidBindValue, param, attribute.Value)
+			}
+			attribute.Value, attribute.Type = value, valueType
+		}
+		condition.Attribute = &attribute
+	}
+	if condition.Quantifier != nil {
+		quantifier := *condition.Quantifier
+		if quantifier.Condition == nil {
+			return types.Condition{}, fmt.Errorf(consts.ErrorMessageInvalidParameter, "quantifier condition")
+		}
+		boundCondition, err := s.BindCondition(*quantifier.Condition, params)
+		if err != nil {
+			return types.Condition{}, err
+		}
+		quantifier.Condition = &boundCondition
+		condition.Quantifier = &quantifier
+	}
+	if condition.Conditions != nil {
+		conditions := make([]*types.Condition, 0, len(condition.Conditions))
+		for _, subCondition := range condition.Conditions {
+			boundCondition, err := s.BindCondition(*subCondition, params)
+			if err != nil {
+				return types.Condition{}, err
+			}
+			conditions = append(conditions, &boundCondition)
+		}
+		condition.Conditions = conditions
+	}
+	return condition, nil
+}
+
+// getParameterLiteral converts a bound parameter value into an attribute value
+// and its type. Lists are only accepted for IN and NOT IN, without commas in
+// their items as the list values are separated by commas, and nil only for =
+// and !=. Times are written in location, the default location when nil.
+func getParameterLiteral(param interface{}, operator string, location *time.Location) (string, valuetype.ValueType, bool) {
+	rValue := reflect.ValueOf(param)
+	for rValue.Kind() == reflect.Ptr || rValue.Kind() == reflect.Interface {
+		if rValue.IsNil() {
+			break
+		}
+		rValue = rValue.Elem()
+	}
+	switch rValue.Kind() {
+	case reflect.Invalid, reflect.Ptr, reflect.Interface:
+		if operator != consts.OperatorEqual && operator != consts.OperatorNotEqual {
+			return "", "", false
+		}
+		return consts.LiteralNull, valuetype.Null, true
+	case reflect.Bool:
+		return strconv.FormatBool(rValue.Bool()), valuetype.Boolean, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rValue.Int(), 10), valuetype.Numeric, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rValue.Uint(), 10), valuetype.Numeric, true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rValue.Float(), 'f', -1, 64), valuetype.Numeric, true
+	case reflect.String:
+		return rValue.String(), valuetype.Alphanumeric, true
+	case reflect.Struct:
+		if timeValue, ok := rValue.Interface().(time.Time); ok {
+			return utils.FormatTimeIn(timeValue, location), valuetype.Date, true
+		}
+	case reflect.Slice, reflect.Array:
+		if operator != consts.OperatorInclude && operator != consts.OperatorExclude {
+			break
+		}
+		values := make([]string, 0, rValue.Len())
+		valueType := valuetype.Numeric
+		for i := 0; i < rValue.Len(); i++ {
+			value, itemType, ok := getParameterLiteral(rValue.Index(i).Interface(), consts.OperatorEqual, location)
+			if !ok || itemType == valuetype.Null || strings.Contains(value, ",") {
+				return "", "", false
+			}
+			if itemType != valuetype.Numeric {
+				valueType = valuetype.Alphanumeric
+			}
+			values = append(values, value)
+		}
+		if len(values) == 0 {
+			break
+		}
+		return strings.Join(values, ","), valueType, true
+	}
+	return "", "", false
+}
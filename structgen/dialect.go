@@ -0,0 +1,188 @@
+package structgen
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"sort"
+	"unicode"
+)
+
+// Dialect is the token table of a condition syntax: the symbols of its
+// comparison and logical operators and its quote characters. Every dialect is
+// parsed to the same condition model. Keyword operators like IN, the AND, OR
+// and NOT keywords, parentheses, prefixes and comments are shared by all.
+type Dialect struct {
+	// Operators maps the comparison operator symbols to the operators of the
+	// condition model, e.g. "==" to "=".
+	Operators map[string]string
+	// LogicalOperators maps the logical operator symbols to AND or OR.
+	LogicalOperators map[string]string
+	// Negation is the symbol negating the following condition, NOT only when
+	// empty.
+	Negation string
+	// Quotes are the characters enclosing quoted values.
+	Quotes string
+
+	symbolRunes map[rune]bool
+	quoteRunes  map[rune]bool
+	// symbolLength is the length in runes of the longest symbol.
+	symbolLength int
+}
+
+// reservedRunes can't be used in dialect symbols, as they mean the same in
+// every dialect.
+var reservedRunes = map[rune]bool{
+	'(':  true,
+	')':  true,
+	',':  true,
+	'.':  true,
+	'_':  true,
+	'\\': true,
+	'#':  true,
+	'/':  true,
+	'*':  true,
+	'+':  true,
+	'-':  true,
+	'%':  true,
+	'$':  true,
+	'@':  true,
+}
+
+// defaultDialect is the dialect of a StructGen not created with New.
+var defaultDialect = func() *Dialect {
+	dialect, err := prepareDialect(DefaultDialect())
+	if err != nil {
+		panic(err)
+	}
+	return dialect
+}()
+
+// DefaultDialect returns the default token table, with && and || as logical
+// operators, which can be changed to derive another dialect.
+func DefaultDialect() Dialect {
+	dialect := Dialect{
+		Operators:        make(map[string]string, len(operatorMap)),
+		LogicalOperators: make(map[string]string, len(logicalOperatorMap)),
+		Negation:         consts.LogicalOperatorNotSyntax,
+		Quotes:           `"'`,
+	}
+	for operator := range operatorMap {
+		dialect.Operators[operator] = operator
+	}
+	for symbol, operator := range logicalOperatorMap {
+		dialect.LogicalOperators[symbol] = operator
+	}
+	return dialect
+}
+
+// New returns a StructGen parsing the syntax of dialect. In a dialect using :
+// as an operator, a parameter like :member_id has to be written apart from the
+// attribute before it, status:paid compares status to paid.
+func New(dialect Dialect) (*StructGen, error) {
+	prepared, err := prepareDialect(dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &StructGen{dialect: prepared}, nil
+}
+
+// prepareDialect checks the symbols of dialect and returns a copy of it with its
+// rune tables, which later changes to the maps of dialect don't affect.
+func prepareDialect(dialect Dialect) (*Dialect, error) {
+	prepared := &Dialect{
+		Operators:        make(map[string]string, len(dialect.Operators)),
+		LogicalOperators: make(map[string]string, len(dialect.LogicalOperators)),
+		Negation:         dialect.Negation,
+		Quotes:           dialect.Quotes,
+		symbolRunes:      make(map[rune]bool),
+		quoteRunes:       make(map[rune]bool),
+	}
+	symbols := make(map[string]interface{})
+	addSymbol := func(kind, symbol string) error {
+		if symbol == "" {
+			return fmt.Errorf(consts.ErrorMessageInvalidSymbol, kind, symbol)
+		}
+		for _, char := range symbol {
+			if !isSymbolRune(char) {
+				return fmt.Errorf(consts.ErrorMessageInvalidSymbol, kind, symbol)
+			}
+		}
+		if _, ok := symbols[symbol]; ok {
+			return fmt.Errorf(consts.ErrorMessageDuplicateSymbol, symbol)
+		}
+		symbols[symbol] = nil
+		for _, char := range symbol {
+			prepared.symbolRunes[char] = true
+		}
+		if length := len([]rune(symbol)); length > prepared.symbolLength {
+			prepared.symbolLength = length
+		}
+		return nil
+	}
+
+	for symbol, operator := range dialect.Operators {
+		if err := addSymbol("operator", symbol); err != nil {
+			return nil, err
+		}
+		if _, ok := operatorMap[operator]; !ok {
+			return nil, fmt.Errorf(consts.ErrorMessageUnknownMapping, symbol, operator)
+		}
+		prepared.Operators[symbol] = operator
+	}
+	for symbol, operator := range dialect.LogicalOperators {
+		if err := addSymbol("logical operator", symbol); err != nil {
+			return nil, err
+		}
+		if operator != consts.LogicalOperatorAnd && operator != consts.LogicalOperatorOr {
+			return nil, fmt.Errorf(consts.ErrorMessageUnknownMapping, symbol, operator)
+		}
+		prepared.LogicalOperators[symbol] = operator
+	}
+	if dialect.Negation != "" {
+		if err := addSymbol("negation", dialect.Negation); err != nil {
+			return nil, err
+		}
+	}
+	for _, char := range dialect.Quotes {
+		if !isSymbolRune(char) || prepared.symbolRunes[char] || prepared.quoteRunes[char] {
+			return nil, fmt.Errorf(consts.ErrorMessageInvalidSymbol, "quote", string(char))
+		}
+		prepared.quoteRunes[char] = true
+	}
+	return prepared, nil
+}
+
+// isSymbolOperator reports whether value is one of the operator or negation
+// symbols of the dialect.
+func (d *Dialect) isSymbolOperator(value string) bool {
+	if d.Negation != "" && value == d.Negation {
+		return true
+	}
+	if _, ok := d.Operators[value]; ok {
+		return true
+	}
+	_, ok := d.LogicalOperators[value]
+	return ok
+}
+
+// getOperatorSymbol returns the symbol of the dialect for the comparison
+// operator of the condition model, the first in sorted order when there are
+// several.
+func (d *Dialect) getOperatorSymbol(operator string) (string, bool) {
+	var symbols []string
+	for symbol, value := range d.Operators {
+		if value == operator {
+			symbols = append(symbols, symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		return "", false
+	}
+	sort.Strings(symbols)
+	return symbols[0], true
+}
+
+// isSymbolRune reports whether char can be used in dialect symbols and quotes.
+func isSymbolRune(char rune) bool {
+	return !reservedRunes[char] && (unicode.IsPunct(char) || unicode.IsSymbol(char))
+}
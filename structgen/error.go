@@ -0,0 +1,29 @@
+package structgen
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+)
+
+// SyntaxError is returned by GenerateCondition when the query can't be parsed.
+// Line and Column are 1-based and point to the first character of Token.
+type SyntaxError struct {
+	Line    int
+	Column  int
+	Token   string
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf(consts.ErrorMessageSyntax, e.Line, e.Column, e.Token, e.Message)
+}
+
+func newSyntaxError(attr *types.TokenAttribute, message string) *SyntaxError {
+	return &SyntaxError{
+		Line:    attr.Line,
+		Column:  attr.Column,
+		Token:   attr.Value,
+		Message: message,
+	}
+}
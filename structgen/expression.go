@@ -0,0 +1,373 @@
+package structgen
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/utils"
+	"strconv"
+	"strings"
+)
+
+// functionArity is the number of arguments a function accepts, a negative max
+// means any number of arguments from min.
+type functionArity struct {
+	min int
+	max int
+}
+
+var (
+	arithmeticOperatorMap = map[string]int{
+		consts.ArithmeticOperatorAdd:      1,
+		consts.ArithmeticOperatorSubtract: 1,
+		consts.ArithmeticOperatorMultiply: 2,
+		consts.ArithmeticOperatorDivide:   2,
+		consts.ArithmeticOperatorModulo:   2,
+	}
+
+	functionMap = map[string]functionArity{
+		consts.FunctionLower:        {min: 1, max: 1},
+		consts.FunctionUpper:        {min: 1, max: 1},
+		consts.FunctionTrim:         {min: 1, max: 1},
+		consts.FunctionLen:          {min: 1, max: 1},
+		consts.FunctionAbs:          {min: 1, max: 1},
+		consts.FunctionYear:         {min: 1, max: 1},
+		consts.FunctionMonth:        {min: 1, max: 1},
+		consts.FunctionDay:          {min: 1, max: 1},
+		consts.FunctionCoalesce:     {min: 2, max: -1},
+		consts.FunctionNow:          {min: 0, max: 0},
+		consts.FunctionToday:        {min: 0, max: 0},
+		consts.FunctionStartOfMonth: {min: 0, max: 0},
+	}
+
+	aggregateMap = map[string]interface{}{
+		consts.AggregateCount: nil,
+		consts.AggregateSum:   nil,
+		consts.AggregateAvg:   nil,
+		consts.AggregateMin:   nil,
+		consts.AggregateMax:   nil,
+	}
+
+	quoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+// buildExpression parses operands joined by arithmetic operators, e.g.
+// price * quantity + fee, from the beginning of attrs and returns the number of
+// consumed tokens. Multiplicative operators take precedence over additive ones.
+// Quoted strings are only accepted as operands of function arguments, and dates
+// and durations can only be added or subtracted.
+func (s *StructGen) buildExpression(attrs []*types.TokenAttribute, isArgument bool) (int, *types.Expression, error) {
+	var (
+		operands  []*types.Expression
+		operators []string
+		operator  *types.TokenAttribute
+	)
+	for i := 0; ; i++ {
+		if i >= len(attrs) || !s.isValue(attrs[i]) && !isSymbol(attrs[i], "(") {
+			return 0, nil, newSyntaxError(attrs[i-1], consts.ErrorMessageMissingOperand)
+		}
+		length, operand, err := s.buildOperand(attrs[i:], isArgument)
+		if err != nil {
+			return 0, nil, err
+		}
+		operands = append(operands, operand)
+		if operator != nil && arithmeticOperatorMap[operator.Value] > 1 &&
+			(s.isDateExpression(operand) || s.isDateExpression(operands[len(operands)-2])) {
+			return 0, nil, newSyntaxError(operator, consts.ErrorMessageInvalidDateArithmetic)
+		}
+		i += length
+		if i >= len(attrs) || !isArithmeticOperator(attrs[i]) {
+			return i, combineExpression(operands, operators), nil
+		}
+		operator = attrs[i]
+		operators = append(operators, operator.Value)
+	}
+}
+
+// buildOperand parses a single operand, a parenthesized expression, a function
+// call, an aggregate, a numeric literal, a duration literal like 7d or an
+// attribute optionally written as a $ prefixed reference.
+func (s *StructGen) buildOperand(attrs []*types.TokenAttribute, isArgument bool) (int, *types.Expression, error) {
+	attr := attrs[0]
+	if isSymbol(attr, "(") {
+		return s.buildGroup(attrs, isArgument)
+	}
+	if len(attrs) > 1 && isSymbol(attrs[1], "(") && !attr.IsAlphanumeric {
+		if _, ok := aggregateMap[strings.ToLower(attr.Value)]; ok {
+			return s.buildAggregate(attrs)
+		}
+		return s.buildFunction(attrs)
+	}
+	if attr.IsAlphanumeric {
+		if !isArgument || (len(attrs) > 1 && isArithmeticOperator(attrs[1])) {
+			return 0, nil, newSyntaxError(attr, consts.ErrorMessageInvalidOperand)
+		}
+		return 1, &types.Expression{Value: attr.Value, Type: valuetype.Alphanumeric}, nil
+	}
+	if isParameter(attr) {
+		return 0, nil, newSyntaxError(attr, consts.ErrorMessageInvalidOperand)
+	}
+	if _, err := strconv.ParseFloat(attr.Value, 64); err == nil {
+		return 1, &types.Expression{Value: attr.Value}, nil
+	}
+	if _, _, ok := utils.SplitDuration(attr.Value); ok {
+		return 1, &types.Expression{Value: attr.Value, Type: valuetype.Duration}, nil
+	}
+	name := strings.TrimPrefix(attr.Value, consts.ReferencePrefix)
+	if name == "" {
+		return 0, nil, newSyntaxError(attr, consts.ErrorMessageInvalidOperand)
+	}
+	return 1, &types.Expression{Name: name}, nil
+}
+
+// buildGroup parses an expression in parentheses, e.g. (a + b) in
+// (a + b) * c, and returns the number of consumed tokens.
+func (s *StructGen) buildGroup(attrs []*types.TokenAttribute, isArgument bool) (int, *types.Expression, error) {
+	open := attrs[0]
+	if len(attrs) < 2 || !s.isValue(attrs[1]) && !isSymbol(attrs[1], "(") {
+		return 0, nil, newSyntaxError(open, consts.ErrorMessageMissingOperand)
+	}
+	if err := s.enter(); err != nil {
+		return 0, nil, err
+	}
+	defer func() {
+		s.depth--
+	}()
+	length, expression, err := s.buildExpression(attrs[1:], isArgument)
+	if err != nil {
+		return 0, nil, err
+	}
+	length++
+	if length >= len(attrs) || !isSymbol(attrs[length], ")") {
+		return 0, nil, newSyntaxError(open, consts.ErrorMessageUnclosedParenthesis)
+	}
+	return length + 1, expression, nil
+}
+
+// buildFunction parses a function call, e.g. coalesce(nickname, name), and
+// checks its name and number of arguments. Literal arguments of registered
+// functions are checked against the declared types.
+func (s *StructGen) buildFunction(attrs []*types.TokenAttribute) (int, *types.Expression, error) {
+	name, open := attrs[0], attrs[1]
+	function := strings.ToLower(name.Value)
+	arity, isBuiltIn := functionMap[function]
+	customFunction, isCustom := s.getRegistry().Function(function)
+	if !isBuiltIn && !isCustom {
+		return 0, nil, newSyntaxError(name, consts.ErrorMessageUnknownFunction)
+	}
+	if err := s.enter(); err != nil {
+		return 0, nil, err
+	}
+	defer func() {
+		s.depth--
+	}()
+	expression := &types.Expression{Function: function}
+	i := 2
+	if i < len(attrs) && isSymbol(attrs[i], ")") {
+		i++
+	} else {
+		for {
+			if i >= len(attrs) {
+				return 0, nil, newSyntaxError(open, consts.ErrorMessageUnclosedParenthesis)
+			}
+			if !s.isValue(attrs[i]) && !isSymbol(attrs[i], "(") {
+				return 0, nil, newSyntaxError(attrs[i-1], consts.ErrorMessageMissingArgument)
+			}
+			length, argument, err := s.buildExpression(attrs[i:], true)
+			if err != nil {
+				return 0, nil, err
+			}
+			expression.Arguments = append(expression.Arguments, argument)
+			i += length
+			if i >= len(attrs) {
+				return 0, nil, newSyntaxError(open, consts.ErrorMessageUnclosedParenthesis)
+			}
+			if isSymbol(attrs[i], ")") {
+				i++
+				break
+			}
+			if !isSymbol(attrs[i], ",") {
+				return 0, nil, newSyntaxError(attrs[i], consts.ErrorMessageExpectedListSeparator)
+			}
+			i++
+		}
+	}
+	count := len(expression.Arguments)
+	if isBuiltIn && (count < arity.min || (arity.max >= 0 && count > arity.max)) ||
+		!isBuiltIn && !customFunction.IsValidArity(count) {
+		return 0, nil, newSyntaxError(name, fmt.Sprintf(consts.ErrorMessageFunctionArguments, function))
+	}
+	if !isBuiltIn {
+		for index, argument := range expression.Arguments {
+			argumentType := customFunction.ArgumentType(index)
+			if !isCompatibleArgument(argument, argumentType) {
+				return 0, nil, newSyntaxError(name, fmt.Sprintf(consts.ErrorMessageInvalidArgumentType, index+1, function, argumentType))
+			}
+		}
+	}
+	return i, expression, nil
+}
+
+// buildAggregate parses an aggregate over a collection, e.g. sum(items.price),
+// whose single argument is the collection attribute.
+func (s *StructGen) buildAggregate(attrs []*types.TokenAttribute) (int, *types.Expression, error) {
+	name, open := attrs[0], attrs[1]
+	aggregate := strings.ToLower(name.Value)
+	if len(attrs) < 3 || isSymbol(attrs[2], ")") {
+		return 0, nil, newSyntaxError(open, consts.ErrorMessageMissingArgument)
+	}
+	attr := attrs[2]
+	if attr.IsAlphanumeric || !s.isValue(attr) || isParameter(attr) {
+		return 0, nil, newSyntaxError(attr, consts.ErrorMessageExpectedAttribute)
+	}
+	if _, err := strconv.ParseFloat(attr.Value, 64); err == nil {
+		return 0, nil, newSyntaxError(attr, consts.ErrorMessageExpectedAttribute)
+	}
+	if len(attrs) < 4 {
+		return 0, nil, newSyntaxError(open, consts.ErrorMessageUnclosedParenthesis)
+	}
+	if !isSymbol(attrs[3], ")") {
+		return 0, nil, newSyntaxError(name, fmt.Sprintf(consts.ErrorMessageFunctionArguments, aggregate))
+	}
+	return 4, &types.Expression{Aggregate: aggregate, Name: attr.Value}, nil
+}
+
+// isCompatibleArgument reports whether a literal argument can be converted to
+// the declared argument type. Attributes and nested expressions are only known
+// when validating.
+func isCompatibleArgument(argument *types.Expression, argumentType valuetype.ValueType) bool {
+	if argument.Name != "" || argument.Function != "" || argument.Operator != "" || argument.Aggregate != "" {
+		return true
+	}
+	switch argumentType {
+	case valuetype.Numeric:
+		return argument.Type != valuetype.Alphanumeric
+	case valuetype.Date:
+		if argument.Type != valuetype.Alphanumeric {
+			return false
+		}
+		_, err := utils.ParseTime(argument.Value)
+		return err == nil
+	case valuetype.Boolean:
+		return false
+	}
+	return true
+}
+
+// combineExpression builds the expression tree of operands joined by operators,
+// grouping left to right within the same precedence.
+func combineExpression(operands []*types.Expression, operators []string) *types.Expression {
+	var (
+		terms         []*types.Expression
+		termOperators []string
+	)
+	term := operands[0]
+	for i, operator := range operators {
+		if arithmeticOperatorMap[operator] > 1 {
+			term = &types.Expression{Operator: operator, Left: term, Right: operands[i+1]}
+			continue
+		}
+		terms = append(terms, term)
+		termOperators = append(termOperators, operator)
+		term = operands[i+1]
+	}
+	terms = append(terms, term)
+
+	expression := terms[0]
+	for i, operator := range termOperators {
+		expression = &types.Expression{Operator: operator, Left: expression, Right: terms[i+1]}
+	}
+	return expression
+}
+
+// formatExpression returns the DSL form of expression.
+func formatExpression(expression *types.Expression) string {
+	switch {
+	case expression.Aggregate != "":
+		return expression.Aggregate + "(" + expression.Name + ")"
+	case expression.Function != "":
+		arguments := make([]string, 0, len(expression.Arguments))
+		for _, argument := range expression.Arguments {
+			arguments = append(arguments, formatExpression(argument))
+		}
+		return expression.Function + "(" + strings.Join(arguments, ", ") + ")"
+	case expression.Operator == "":
+		if expression.Name != "" {
+			if _, err := strconv.ParseFloat(expression.Name, 64); err == nil {
+				return consts.ReferencePrefix + expression.Name
+			}
+			if _, _, ok := utils.SplitDuration(expression.Name); ok {
+				return consts.ReferencePrefix + expression.Name
+			}
+			if _, isReserved := reservedWordMap[strings.ToLower(expression.Name)]; isReserved || !isWord(expression.Name) {
+				return consts.ReferencePrefix + expression.Name
+			}
+			return expression.Name
+		}
+		if expression.Type == valuetype.Alphanumeric {
+			return `"` + quoteReplacer.Replace(expression.Value) + `"`
+		}
+		return expression.Value
+	}
+	precedence := arithmeticOperatorMap[expression.Operator]
+	left := formatExpression(expression.Left)
+	if isLowerPrecedence(expression.Left, precedence, false) {
+		left = "(" + left + ")"
+	}
+	right := formatExpression(expression.Right)
+	if isLowerPrecedence(expression.Right, precedence, true) {
+		right = "(" + right + ")"
+	}
+	return left + " " + expression.Operator + " " + right
+}
+
+// isLowerPrecedence reports whether the operand needs parentheses to be
+// evaluated before an operator of the given precedence. Operators group left to
+// right, so a right operand of the same precedence needs them too.
+func isLowerPrecedence(operand *types.Expression, precedence int, isRight bool) bool {
+	if operand.Operator == "" {
+		return false
+	}
+	operandPrecedence := arithmeticOperatorMap[operand.Operator]
+	return operandPrecedence < precedence || (isRight && operandPrecedence == precedence)
+}
+
+// isDateExpression reports whether expression computes a date or a duration, a
+// date function like now(), a duration literal like 7d or a sum of them.
+func (s *StructGen) isDateExpression(expression *types.Expression) bool {
+	switch {
+	case expression.Type == valuetype.Duration:
+		return true
+	case expression.Function != "":
+		switch expression.Function {
+		case consts.FunctionNow, consts.FunctionToday, consts.FunctionStartOfMonth:
+			return true
+		}
+		function, ok := s.getRegistry().Function(expression.Function)
+		return ok && function.ReturnType == valuetype.Date
+	case expression.Operator == consts.ArithmeticOperatorAdd || expression.Operator == consts.ArithmeticOperatorSubtract:
+		return s.isDateExpression(expression.Left) || s.isDateExpression(expression.Right)
+	}
+	return false
+}
+
+// isOperand reports whether expression is a single attribute or literal, like a
+// in (a) = 1.
+func isOperand(expression *types.Expression) bool {
+	return expression.Operator == "" && expression.Function == "" && expression.Aggregate == ""
+}
+
+// isArithmetic reports whether expression computes a number with an arithmetic
+// operator, rather than being a function call.
+func isArithmetic(expression *types.Expression) bool {
+	return expression != nil && expression.Operator != ""
+}
+
+func isArithmeticOperator(attr *types.TokenAttribute) bool {
+	if attr.IsAlphanumeric {
+		return false
+	}
+	_, ok := arithmeticOperatorMap[attr.Value]
+	return ok
+}
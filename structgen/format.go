@@ -0,0 +1,200 @@
+package structgen
+
+import (
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"strings"
+	"unicode"
+)
+
+// reservedWordMap holds the words that can't be written as unquoted values or
+// attribute names because the parser reads them as keywords.
+var reservedWordMap = func() map[string]interface{} {
+	words := map[string]interface{}{
+		consts.LiteralTrue:                         nil,
+		consts.LiteralFalse:                        nil,
+		consts.LiteralNull:                         nil,
+		strings.ToLower(consts.LogicalOperatorAnd): nil,
+		strings.ToLower(consts.LogicalOperatorOr):  nil,
+		strings.ToLower(consts.LogicalOperatorNot): nil,
+	}
+	for operator := range keywordOperatorMap {
+		for _, word := range strings.Fields(operator) {
+			words[strings.ToLower(word)] = nil
+		}
+	}
+	return words
+}()
+
+// Format returns the canonical DSL form of condition in the default dialect,
+// which GenerateCondition parses back to the same condition. Parentheses are
+// only written where the grouping differs from the one given by operator
+// precedence, and values are quoted when they wouldn't be read back as the
+// same value and type.
+func Format(condition types.Condition) string {
+	if len(condition.Conditions) > 0 && !condition.Negate {
+		return formatConditions(condition.Conditions)
+	}
+	return formatCondition(&condition, false)
+}
+
+// formatConditions joins sibling conditions with their logical operators.
+func formatConditions(conditions []*types.Condition) string {
+	isOr := len(conditions) > 1 && !isAndList(conditions)
+	var builder strings.Builder
+	for i, condition := range conditions {
+		if i > 0 {
+			if condition.Operator == consts.LogicalOperatorOr {
+				builder.WriteString(" " + consts.LogicalOperatorOrSyntax + " ")
+			} else {
+				builder.WriteString(" " + consts.LogicalOperatorAndSyntax + " ")
+			}
+		}
+		builder.WriteString(formatCondition(condition, isOr))
+	}
+	return builder.String()
+}
+
+// formatCondition returns the DSL form of a single condition. A group of AND
+// joined conditions between OR joined siblings needs no parentheses, as the
+// parser groups it the same way.
+func formatCondition(condition *types.Condition, isOr bool) string {
+	var negation string
+	if condition.Negate {
+		negation = consts.LogicalOperatorNotSyntax
+	}
+	switch {
+	case len(condition.Conditions) > 0:
+		conditions := formatConditions(condition.Conditions)
+		if isOr && !condition.Negate && len(condition.Conditions) > 1 && isAndList(condition.Conditions) {
+			return conditions
+		}
+		return negation + "(" + conditions + ")"
+	case condition.Quantifier != nil:
+		return negation + formatQuantifier(condition.Quantifier)
+	case condition.Attribute != nil && *condition.Attribute != (types.Attribute{}):
+		return negation + formatAttribute(condition.Attribute)
+	}
+	return ""
+}
+
+func formatQuantifier(quantifier *types.Quantifier) string {
+	var condition string
+	if quantifier.Condition != nil {
+		condition = Format(*quantifier.Condition)
+	}
+	return quantifier.Operator + "(" + quantifier.Name + ", " + condition + ")"
+}
+
+func formatAttribute(attribute *types.Attribute) string {
+	name := formatWord(attribute.Name, "")
+	if attribute.Left != nil {
+		name = formatExpression(attribute.Left)
+	}
+	operator := attribute.Operator
+	if operator == "" {
+		operator = consts.OperatorEqual
+	}
+
+	var value string
+	switch {
+	case attribute.Right != nil:
+		value = formatExpression(attribute.Right)
+	case attribute.Type == valuetype.Parameter:
+		value = consts.ParameterPrefix + attribute.Value
+	case operator == consts.OperatorIsNull || operator == consts.OperatorIsNotNull:
+		return name + " " + operator
+	case operator == consts.OperatorInclude || operator == consts.OperatorExclude:
+		value = formatList(attribute.Value, attribute.Type)
+	case operator == consts.OperatorBetween || operator == consts.OperatorNotBetween:
+		bounds := strings.SplitN(attribute.Value, ",", 2)
+		value = formatLiteral(bounds[0], attribute.Type)
+		if len(bounds) > 1 {
+			value += " " + consts.LogicalOperatorAnd + " " + formatLiteral(bounds[1], attribute.Type)
+		}
+	default:
+		value = formatLiteral(attribute.Value, attribute.Type)
+	}
+	return name + " " + operator + " " + value
+}
+
+// formatList returns the DSL form of a comma separated list of values. The type
+// of a list is given by all its values, so the values of a numeric or date list
+// are written unquoted when the list is read back as the same type.
+func formatList(value string, valueType valuetype.ValueType) string {
+	values := strings.Split(value, ",")
+	isList := valueType == valuetype.Numeric || valueType == valuetype.Date
+	for _, item := range values {
+		isList = isList && isWord(item)
+	}
+	if !isList || getValueType(value) != valueType {
+		for i := range values {
+			values[i] = formatLiteral(values[i], valueType)
+		}
+	}
+	return "(" + strings.Join(values, ", ") + ")"
+}
+
+// formatLiteral returns the DSL form of a value of the given type.
+func formatLiteral(value string, valueType valuetype.ValueType) string {
+	switch valueType {
+	case valuetype.Reference:
+		return consts.ReferencePrefix + value
+	case valuetype.Parameter:
+		return consts.ParameterPrefix + value
+	case valuetype.Boolean, valuetype.Null:
+		return strings.ToLower(value)
+	case valuetype.Numeric, valuetype.Date:
+		if isWord(value) && getValueType(value) == valueType {
+			return value
+		}
+	case "":
+		// An untyped value, e.g. from JSON, is written as the number or the date
+		// it holds, as quoting it would make it a string.
+		if isWord(value) && getValueType(value) != valuetype.Alphanumeric {
+			return value
+		}
+	}
+	return formatWord(value, valuetype.Alphanumeric)
+}
+
+// formatWord returns value unquoted when the parser reads it back as the same
+// word, of valueType when it's set, or quoted otherwise.
+func formatWord(value string, valueType valuetype.ValueType) string {
+	first := []rune(value + " ")[0]
+	_, isReserved := reservedWordMap[strings.ToLower(value)]
+	if isWord(value) && !isReserved && (unicode.IsLetter(first) || first == '_') &&
+		(valueType == "" || getValueType(value) == valueType) {
+		return value
+	}
+	return `"` + quoteReplacer.Replace(value) + `"`
+}
+
+// isWord reports whether the tokenizer reads value as a single unquoted word
+// without any special meaning.
+func isWord(value string) bool {
+	if value == "" || strings.HasPrefix(value, "#") || strings.HasPrefix(value, "//") ||
+		strings.HasPrefix(value, "/*") || strings.HasPrefix(value, consts.ReferencePrefix) ||
+		strings.HasPrefix(value, consts.ParameterPrefix) || strings.HasPrefix(value, consts.ConditionPrefix) {
+		return false
+	}
+	for _, char := range value {
+		if unicode.IsSpace(char) || defaultDialect.symbolRunes[char] || defaultDialect.quoteRunes[char] ||
+			char == '(' || char == ')' || char == ',' {
+			return false
+		}
+	}
+	attrs, err := getTokenAttributes(value)
+	return err == nil && len(attrs) == 1 && attrs[0].Value == value
+}
+
+// isAndList reports whether sibling conditions are only joined by AND.
+func isAndList(conditions []*types.Condition) bool {
+	for _, condition := range conditions[1:] {
+		if condition.Operator == consts.LogicalOperatorOr {
+			return false
+		}
+	}
+	return true
+}
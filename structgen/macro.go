@@ -0,0 +1,140 @@
+package structgen
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"strings"
+)
+
+// expansion holds the named conditions expanded while parsing a query or a rule
+// file, so that each one is parsed once, and the number of conditions and
+// expressions they add, which is bounded by the token limit.
+type expansion struct {
+	conditions map[string]*expandedCondition
+	count      int
+}
+
+// expandedCondition is a parsed named condition with its number of conditions
+// and expressions and the nesting depth it adds.
+type expandedCondition struct {
+	condition types.Condition
+	count     int
+	depth     int
+}
+
+func newExpansion() *expansion {
+	return &expansion{conditions: make(map[string]*expandedCondition)}
+}
+
+// expandCondition returns the named condition referenced by attr, e.g.
+// @tech_divisions, parsed once from the rules of the file being parsed or else
+// from the registry. Referencing a condition that is being expanded is an
+// error rather than an endless expansion, and so is exceeding the token limit
+// with the conditions and expressions of the expanded ones.
+func (s *StructGen) expandCondition(attr *types.TokenAttribute) (types.Condition, error) {
+	name := strings.TrimPrefix(attr.Value, consts.ConditionPrefix)
+	for i, expanding := range s.expanding {
+		if expanding != name {
+			continue
+		}
+		references := make([]string, 0, len(s.expanding)-i+1)
+		for _, reference := range append(s.expanding[i:], name) {
+			references = append(references, consts.ConditionPrefix+reference)
+		}
+		message := fmt.Sprintf(consts.ErrorMessageCyclicCondition, strings.Join(references, " -> "))
+		return types.Condition{}, newSyntaxError(attr, message)
+	}
+
+	if s.expansion == nil {
+		s.expansion = newExpansion()
+	}
+	expanded, ok := s.expansion.conditions[name]
+	if !ok {
+		parser := *s
+		parser.expanding = append(append([]string(nil), s.expanding...), name)
+		parser.peak = s.depth
+		count := s.expansion.count
+		condition, err := parser.parseCondition(attr, name)
+		if err != nil {
+			return types.Condition{}, err
+		}
+		expanded = &expandedCondition{condition: condition, count: countConditions(&condition), depth: parser.peak - s.depth}
+		s.expansion.count = count
+		s.expansion.conditions[name] = expanded
+	}
+	limits := s.Limits.WithDefaults()
+	s.expansion.count += expanded.count
+	if err := types.CheckLimit(consts.LimitTokens, limits.MaxTokens, s.expansion.count); err != nil {
+		return types.Condition{}, err
+	}
+	if err := types.CheckLimit(consts.LimitDepth, limits.MaxDepth, s.depth+expanded.depth); err != nil {
+		return types.Condition{}, err
+	}
+	if s.depth+expanded.depth > s.peak {
+		s.peak = s.depth + expanded.depth
+	}
+	return expanded.condition, nil
+}
+
+// parseCondition parses the named condition referenced by attr one level
+// deeper than the reference.
+func (s *StructGen) parseCondition(attr *types.TokenAttribute, name string) (types.Condition, error) {
+	if err := s.enter(); err != nil {
+		return types.Condition{}, err
+	}
+	if attrs, ok := s.rules[name]; ok {
+		_, condition, err := s.buildCondition(types.Condition{}, attrs, nil)
+		return condition, err
+	}
+	query, ok := s.getRegistry().Condition(name)
+	if !ok {
+		return types.Condition{}, newSyntaxError(attr, consts.ErrorMessageUndefinedCondition)
+	}
+	attrs, err := s.getTokenAttributes(query)
+	if err != nil {
+		return types.Condition{}, fmt.Errorf(consts.ErrorMessageInvalidCondition, consts.ConditionPrefix, name, err)
+	}
+	_, condition, err := s.buildCondition(types.Condition{}, attrs, nil)
+	if err != nil {
+		return types.Condition{}, fmt.Errorf(consts.ErrorMessageInvalidCondition, consts.ConditionPrefix, name, err)
+	}
+	return condition, nil
+}
+
+// countConditions returns the number of conditions and expressions of
+// condition, as counted against the token limit.
+func countConditions(condition *types.Condition) int {
+	if condition == nil {
+		return 0
+	}
+	count := 1
+	if attribute := condition.Attribute; attribute != nil {
+		count += countExpressions(attribute.Left) + countExpressions(attribute.Right)
+	}
+	if quantifier := condition.Quantifier; quantifier != nil {
+		count += countConditions(quantifier.Condition)
+	}
+	for _, subCondition := range condition.Conditions {
+		count += countConditions(subCondition)
+	}
+	return count
+}
+
+func countExpressions(expression *types.Expression) int {
+	if expression == nil {
+		return 0
+	}
+	count := 1 + countExpressions(expression.Left) + countExpressions(expression.Right)
+	for _, argument := range expression.Arguments {
+		count += countExpressions(argument)
+	}
+	return count
+}
+
+// isConditionReference reports whether attr references a named condition like
+// @tech_divisions.
+func isConditionReference(attr *types.TokenAttribute) bool {
+	return !attr.IsAlphanumeric && len(attr.Value) > len(consts.ConditionPrefix) &&
+		strings.HasPrefix(attr.Value, consts.ConditionPrefix)
+}
@@ -0,0 +1,115 @@
+package structgen
+
+import (
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"regexp"
+	"strings"
+)
+
+var ruleNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// rule is a named condition of a rule file being parsed.
+type rule struct {
+	header *types.TokenAttribute
+	name   string
+	attrs  []*types.TokenAttribute
+}
+
+// GenerateRules parses a rule file holding several named conditions, e.g.
+//
+//	# customers worth a call
+//	vip: spend > 1000
+//	  && tier = gold
+//	churned: last_order_at < now() - 90d
+//
+// A rule starts at a line beginning with its name followed by a colon, and its
+// condition runs until the next rule, so it can span several lines. Rules can
+// reference each other as @name. With a dialect using : as an operator, a
+// condition line starting like status:paid has to be indented, as it would
+// start a rule otherwise.
+func (s *StructGen) GenerateRules(text string) (map[string]types.Condition, error) {
+	tokenAttributes, err := s.getTokenAttributes(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []*rule
+	for i := 0; i < len(tokenAttributes); i++ {
+		attr := tokenAttributes[i]
+		name, rest, length, ok := splitRuleName(tokenAttributes, i)
+		if !ok {
+			if len(rules) == 0 {
+				return nil, newSyntaxError(attr, consts.ErrorMessageExpectedRuleName)
+			}
+			current := rules[len(rules)-1]
+			current.attrs = append(current.attrs, attr)
+			continue
+		}
+		current := &rule{header: attr, name: name}
+		if rest != nil {
+			current.attrs = append(current.attrs, rest)
+		}
+		rules = append(rules, current)
+		i += length - 1
+	}
+
+	parser := *s
+	parser.expansion = newExpansion()
+	parser.rules = make(map[string][]*types.TokenAttribute, len(rules))
+	for _, current := range rules {
+		if _, ok := parser.rules[current.name]; ok {
+			return nil, newSyntaxError(current.header, consts.ErrorMessageDuplicateRuleName)
+		}
+		if len(current.attrs) == 0 {
+			return nil, newSyntaxError(current.header, consts.ErrorMessageEmptyRule)
+		}
+		parser.rules[current.name] = current.attrs
+	}
+
+	conditions := make(map[string]types.Condition, len(rules))
+	for _, current := range rules {
+		if expanded, ok := parser.expansion.conditions[current.name]; ok {
+			conditions[current.name] = expanded.condition
+			continue
+		}
+		parser.expanding = []string{current.name}
+		_, condition, err := parser.buildCondition(types.Condition{}, current.attrs, nil)
+		if err != nil {
+			return nil, err
+		}
+		conditions[current.name] = condition
+	}
+	return conditions, nil
+}
+
+// splitRuleName returns the rule name of a line starting with name: and the
+// number of tokens it takes, along with the token of the condition glued to
+// it, e.g. spend for vip:spend. In a dialect using : as an operator the colon
+// is a token of its own, which still ends the name at the start of a line.
+func splitRuleName(attrs []*types.TokenAttribute, index int) (string, *types.TokenAttribute, int, bool) {
+	attr := attrs[index]
+	if attr.Column != 1 || attr.IsAlphanumeric {
+		return "", nil, 0, false
+	}
+	if index+1 < len(attrs) && ruleNameRegex.MatchString(attr.Value) {
+		next := attrs[index+1]
+		if next.Value == ":" && !next.IsAlphanumeric && next.Line == attr.Line &&
+			next.Column == attr.Column+len([]rune(attr.Value)) {
+			return attr.Value, nil, 2, true
+		}
+	}
+	colon := strings.Index(attr.Value, ":")
+	if colon < 0 || !ruleNameRegex.MatchString(attr.Value[:colon]) {
+		return "", nil, 0, false
+	}
+	name, value := attr.Value[:colon], attr.Value[colon+1:]
+	if value == "" {
+		return name, nil, 1, true
+	}
+	return name, &types.TokenAttribute{
+		Value:  value,
+		Line:   attr.Line,
+		Column: attr.Column + len([]rune(name)) + 1,
+	}, 1, true
+}
@@ -1,176 +1,745 @@
 package structgen
 
 import (
-	"bytes"
+	"fmt"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/registry"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/utils"
+	"regexp"
+	"strings"
 	"time"
 )
 
+// StructGen parses conditions. Registry holds the custom functions, operators
+// and named conditions it accepts, registry.Default when nil. Limits bounds the
+// queries it accepts, exceeding them returns a *types.LimitError. Location is
+// the location of times bound to parameters, the default location of utils
+// when nil. A StructGen created with New parses the syntax of its dialect, the
+// default one otherwise.
 type StructGen struct {
+	Registry *registry.Registry
+	Limits   types.Limits
+	Location *time.Location
+
+	dialect   *Dialect
+	rules     map[string][]*types.TokenAttribute
+	expanding []string
+	expansion *expansion
+	depth     int
+	// peak is the deepest nesting reached, see enter.
+	peak int
 }
 
+const maxKeywordOperatorLength = 3
+
 var (
 	operatorMap = map[string]interface{}{
 		consts.OperatorEqual:            nil,
+		consts.OperatorNotEqual:         nil,
 		consts.OperatorLessThan:         nil,
 		consts.OperatorGreaterThan:      nil,
 		consts.OperatorLessThanEqual:    nil,
 		consts.OperatorGreaterThanEqual: nil,
+		consts.OperatorRegex:            nil,
+	}
+
+	keywordOperatorMap = map[string]interface{}{
+		consts.OperatorInclude:    nil,
+		consts.OperatorExclude:    nil,
+		consts.OperatorBetween:    nil,
+		consts.OperatorNotBetween: nil,
+		consts.OperatorIsNull:     nil,
+		consts.OperatorIsNotNull:  nil,
+		consts.OperatorLike:       nil,
+		consts.OperatorStartsWith: nil,
+		consts.OperatorEndsWith:   nil,
+		consts.OperatorContains:   nil,
+	}
+
+	quantifierMap = map[string]interface{}{
+		consts.QuantifierAny:  nil,
+		consts.QuantifierAll:  nil,
+		consts.QuantifierNone: nil,
 	}
 
 	logicalOperatorMap = map[string]string{
 		consts.LogicalOperatorAndSyntax: consts.LogicalOperatorAnd,
 		consts.LogicalOperatorOrSyntax:  consts.LogicalOperatorOr,
 	}
+
+	// logicalKeywordMap holds the logical operator keywords of every dialect,
+	// matched case insensitively.
+	logicalKeywordMap = map[string]string{
+		consts.LogicalOperatorAnd: consts.LogicalOperatorAnd,
+		consts.LogicalOperatorOr:  consts.LogicalOperatorOr,
+	}
 )
 
 func (s *StructGen) GenerateCondition(query string) (types.Condition, error) {
-	tokenAttributes := getTokenAttributes(query)
+	tokenAttributes, err := s.getTokenAttributes(query)
+	if err != nil {
+		return types.Condition{}, err
+	}
 	if len(tokenAttributes) == 0 {
 		return types.Condition{Attribute: &types.Attribute{}}, nil
 	}
-	_, condition := buildCondition(types.Condition{}, tokenAttributes)
+	parser := *s
+	parser.expansion = newExpansion()
+	_, condition, err := parser.buildCondition(types.Condition{}, tokenAttributes, nil)
+	if err != nil {
+		return types.Condition{}, err
+	}
 	return condition, nil
 }
 
-func buildCondition(condition types.Condition, attrs []*types.TokenAttribute) (int, types.Condition) {
+// buildCondition parses conditions joined by logical operators until the end of
+// attrs or, when open is set, until the parenthesis that closes open. It returns
+// the number of consumed tokens.
+func (s *StructGen) buildCondition(condition types.Condition, attrs []*types.TokenAttribute, open *types.TokenAttribute) (int, types.Condition, error) {
 	var (
-		conditionItem *types.Condition
-		lastPos       int
-		operator      string
+		operator     string
+		operatorAttr *types.TokenAttribute
+		isNegated    bool
 	)
+	isExpectingCondition := true
 	for i := 0; i < len(attrs); i++ {
-		lastPos = i
 		attr := attrs[i]
-		if attr.HasCalled {
+		if !isExpectingCondition {
+			if isSymbol(attr, ")") {
+				if open == nil {
+					return i, condition, newSyntaxError(attr, consts.ErrorMessageUnbalancedParenthesis)
+				}
+				condition.Conditions = groupByPrecedence(condition.Conditions)
+				return i + 1, condition, nil
+			}
+			val, ok := s.getLogicalOperator(attr)
+			if !ok {
+				if s.isUnknownOperator(attr) {
+					return i, condition, newSyntaxError(attr, consts.ErrorMessageUnknownOperator)
+				}
+				return i, condition, newSyntaxError(attr, consts.ErrorMessageExpectedLogicalOperator)
+			}
+			operator = val
+			operatorAttr = attr
+			isExpectingCondition = true
 			continue
 		}
-		attr.HasCalled = true
-		if attr.Value == ")" {
-			break
+
+		if s.isNegation(attr) {
+			isNegated = !isNegated
+			operatorAttr = attr
+			continue
+		}
+		if operatorAttr != nil && !s.isValue(attr) && !isSymbol(attr, "(") {
+			return i, condition, newSyntaxError(operatorAttr, consts.ErrorMessageMissingCondition)
 		}
-		if attr.Value == "(" {
-			newCondition := types.Condition{
-				Operator: operator,
+		if isSymbol(attr, ")") {
+			if open != nil {
+				return i, condition, newSyntaxError(open, consts.ErrorMessageEmptyGroup)
 			}
-			lastPos, resp := buildCondition(newCondition, attrs[i+1:])
-			condition.Conditions = append(condition.Conditions, &resp)
-			i = i + lastPos + 1
-			continue
+			return i, condition, newSyntaxError(attr, consts.ErrorMessageUnbalancedParenthesis)
 		}
-
-		if val, ok := logicalOperatorMap[attr.Value]; ok {
-			operator = val
-			conditionItem = nil
-		} else if _, ok := operatorMap[attr.Value]; ok {
-			if conditionItem != nil {
-				conditionItem.Attribute.Operator = attr.Value
+		if isSymbol(attr, "(") && !s.isExpressionGroup(attrs[i:]) {
+			if err := s.enter(); err != nil {
+				return i, condition, err
+			}
+			length, group, err := s.buildCondition(types.Condition{Operator: operator}, attrs[i+1:], attr)
+			s.depth--
+			if err != nil {
+				return i, condition, err
+			}
+			group.Negate = isNegated
+			condition.Conditions = append(condition.Conditions, &group)
+			i += length
+		} else if isConditionReference(attr) {
+			group, err := s.expandCondition(attr)
+			if err != nil {
+				return i, condition, err
+			}
+			group.Operator, group.Negate = operator, isNegated
+			condition.Conditions = append(condition.Conditions, &group)
+		} else if isQuantifier(attrs[i:]) {
+			length, quantifier, err := s.buildQuantifier(attrs[i:])
+			if err != nil {
+				return i, condition, err
 			}
+			condition.Conditions = append(condition.Conditions, &types.Condition{
+				Operator:   operator,
+				Negate:     isNegated,
+				Quantifier: quantifier,
+			})
+			i += length - 1
 		} else {
-			if conditionItem == nil {
-				conditionItem = &types.Condition{
-					Attribute: &types.Attribute{
-						Name: attr.Value,
-					},
-				}
-				conditionItem.Attribute = &types.Attribute{
-					Name: attr.Value,
-				}
-			} else {
-				conditionItem.Attribute.Value = attr.Value
-				if !attr.IsAlphanumeric {
-					conditionItem.Attribute.Type = getValueType(attr.Value)
-				}
-				if condition.Conditions == nil {
-					condition.Conditions = []*types.Condition{}
-				}
-				conditionItem.Operator = operator
-				condition.Conditions = append(condition.Conditions, conditionItem)
-			}
-		}
-	}
-	return lastPos, condition
-}
-
-func getTokenAttributes(query string) []*types.TokenAttribute {
-	var tokenAttributes []*types.TokenAttribute
-	buffer := &bytes.Buffer{}
-	isOpenQuote := false
-	isAlphanumeric := false
-	for _, char := range query {
-		switch char {
-		case ' ', '\n', '\'':
-			if !isOpenQuote {
-				continue
-			} else {
-				buffer.WriteRune(char)
-			}
-		case '|', '&', '<', '>':
-			if buffer.Len() > 0 {
-				bufBytes := buffer.Bytes()
-				switch bufBytes[0] {
-				case consts.ByteVerticalBar:
-					tokenAttributes = appendAttribute(tokenAttributes, buffer, consts.LogicalOperatorOrSyntax, isAlphanumeric)
-					isAlphanumeric = false
-				case consts.ByteAmpersand:
-					tokenAttributes = appendAttribute(tokenAttributes, buffer, consts.LogicalOperatorAndSyntax, isAlphanumeric)
-					isAlphanumeric = false
-				default:
-					tokenAttributes = appendAttribute(tokenAttributes, buffer, string(bufBytes), isAlphanumeric)
-					isAlphanumeric = false
-					buffer.WriteRune(char)
-				}
-			} else {
-				buffer.WriteRune(char)
-			}
-		case '=', '(', ')':
-			if buffer.Len() > 0 {
-				bufBytes := buffer.Bytes()
-				switch bufBytes[0] {
-				case consts.ByteLessThan, consts.ByteGreaterThan:
-					tokenAttributes = appendAttribute(tokenAttributes, buffer, string(bufBytes)+string(char), isAlphanumeric)
-					isAlphanumeric = false
-					continue
-				default:
-					tokenAttributes = appendAttribute(tokenAttributes, buffer, string(bufBytes), isAlphanumeric)
-					isAlphanumeric = false
-				}
+			length, attribute, err := s.buildAttribute(attrs[i:])
+			if err != nil {
+				return i, condition, err
 			}
-			tokenAttributes = append(tokenAttributes, &types.TokenAttribute{
-				Value: string(char),
+			condition.Conditions = append(condition.Conditions, &types.Condition{
+				Operator:  operator,
+				Negate:    isNegated,
+				Attribute: attribute,
 			})
-		case '"':
-			isOpenQuote = !isOpenQuote
-			if !isOpenQuote {
-				isAlphanumeric = true
-			}
-		default:
-			if buffer.Len() > 0 {
-				bufByte := buffer.Bytes()[0]
-				if bufByte == consts.ByteLessThan || bufByte == consts.ByteGreaterThan {
-					tokenAttributes = appendAttribute(tokenAttributes, buffer, string(bufByte), isAlphanumeric)
-					isAlphanumeric = false
-				}
+			i += length - 1
+		}
+		operatorAttr = nil
+		isNegated = false
+		isExpectingCondition = false
+	}
+	if operatorAttr != nil {
+		return len(attrs), condition, newSyntaxError(operatorAttr, consts.ErrorMessageMissingCondition)
+	}
+	if open != nil {
+		if len(condition.Conditions) == 0 {
+			return len(attrs), condition, newSyntaxError(open, consts.ErrorMessageEmptyGroup)
+		}
+		return len(attrs), condition, newSyntaxError(open, consts.ErrorMessageUnclosedParenthesis)
+	}
+	condition.Conditions = groupByPrecedence(condition.Conditions)
+	return len(attrs), condition, nil
+}
+
+// groupByPrecedence wraps runs of AND-joined conditions into groups when they
+// are mixed with OR, so a || b && c becomes a || (b && c) as it does in SQL.
+func groupByPrecedence(conditions []*types.Condition) []*types.Condition {
+	var hasAnd, hasOr bool
+	for i, condition := range conditions {
+		if i == 0 {
+			continue
+		}
+		if condition.Operator == consts.LogicalOperatorOr {
+			hasOr = true
+		} else {
+			hasAnd = true
+		}
+	}
+	if !hasAnd || !hasOr {
+		return conditions
+	}
+
+	var (
+		grouped []*types.Condition
+		run     []*types.Condition
+	)
+	appendRun := func() {
+		if len(run) == 1 {
+			grouped = append(grouped, run[0])
+			return
+		}
+		group := &types.Condition{
+			Operator:   run[0].Operator,
+			Conditions: run,
+		}
+		run[0].Operator = ""
+		grouped = append(grouped, group)
+	}
+	for i, condition := range conditions {
+		if i > 0 && condition.Operator == consts.LogicalOperatorOr {
+			appendRun()
+			run = nil
+		}
+		run = append(run, condition)
+	}
+	appendRun()
+	return grouped
+}
+
+// buildQuantifier parses a quantifier over the elements of a slice attribute,
+// e.g. any(items, sku = "A1" && qty > 2), and returns the number of consumed
+// tokens.
+func (s *StructGen) buildQuantifier(attrs []*types.TokenAttribute) (int, *types.Quantifier, error) {
+	open := attrs[1]
+	if len(attrs) < 3 || attrs[2].IsAlphanumeric || !s.isValue(attrs[2]) {
+		return 0, nil, newSyntaxError(open, consts.ErrorMessageExpectedAttribute)
+	}
+	name := attrs[2]
+	if len(attrs) < 4 || !isSymbol(attrs[3], ",") {
+		return 0, nil, newSyntaxError(name, consts.ErrorMessageExpectedQuantifier)
+	}
+	if err := s.enter(); err != nil {
+		return 0, nil, err
+	}
+	length, condition, err := s.buildCondition(types.Condition{}, attrs[4:], open)
+	s.depth--
+	if err != nil {
+		return 0, nil, err
+	}
+	return length + 4, &types.Quantifier{
+		Operator:  strings.ToLower(attrs[0].Value),
+		Name:      name.Value,
+		Condition: &condition,
+	}, nil
+}
+
+// buildAttribute parses a single comparison, e.g. id = 1, from the beginning of
+// attrs and returns the number of consumed tokens.
+func (s *StructGen) buildAttribute(attrs []*types.TokenAttribute) (int, *types.Attribute, error) {
+	name := attrs[0]
+	isGroup := isSymbol(name, "(")
+	if !s.isValue(name) && !isGroup {
+		if s.isUnknownOperator(name) {
+			return 0, nil, newSyntaxError(name, consts.ErrorMessageUnknownOperator)
+		}
+		return 0, nil, newSyntaxError(name, consts.ErrorMessageExpectedAttribute)
+	}
+	attribute := &types.Attribute{
+		Name: name.Value,
+	}
+	length := 1
+	if isGroup || len(attrs) > 1 && (isArithmeticOperator(attrs[1]) || isSymbol(attrs[1], "(")) {
+		expressionLength, expression, err := s.buildExpression(attrs, false)
+		if err != nil {
+			return 0, nil, err
+		}
+		attribute.Name, attribute.Left = formatExpression(expression), expression
+		if isOperand(expression) {
+			attribute.Name, attribute.Left = expression.Name+expression.Value, nil
+		}
+		length = expressionLength
+	}
+	if s.isPredicate(attribute.Left) && (len(attrs) <= length || !s.hasOperator(attrs[length:])) {
+		attribute.Operator, attribute.Value, attribute.Type = consts.OperatorEqual, consts.LiteralTrue, valuetype.Boolean
+		return length, attribute, nil
+	}
+	if len(attrs) <= length {
+		return 0, nil, newSyntaxError(name, consts.ErrorMessageMissingOperator)
+	}
+	operator, operatorLength := s.getOperator(attrs[length:])
+	if operatorLength == 0 {
+		if foreign, symbol, ok := s.getForeignOperator(attrs[length:]); ok {
+			return 0, nil, newSyntaxError(attrs[length], fmt.Sprintf(consts.ErrorMessageForeignOperator, foreign, symbol))
+		}
+		if s.isUnknownOperator(attrs[length]) {
+			return 0, nil, newSyntaxError(attrs[length], consts.ErrorMessageUnknownOperator)
+		}
+		return 0, nil, newSyntaxError(name, consts.ErrorMessageMissingOperator)
+	}
+	attribute.Operator = operator
+	length += operatorLength
+	operatorAttr := attrs[length-1]
+	if isArithmetic(attribute.Left) && !isComparisonOperator(operator) {
+		return 0, nil, newSyntaxError(operatorAttr, consts.ErrorMessageInvalidExpression)
+	}
+
+	switch operator {
+	case consts.OperatorInclude, consts.OperatorExclude:
+		if len(attrs) > length && isParameter(attrs[length]) {
+			attribute.Value, attribute.Type = getLiteral(attrs[length])
+			length++
+			break
+		}
+		listLength, err := s.buildListValue(attribute, operatorAttr, attrs[length:])
+		if err != nil {
+			return 0, nil, err
+		}
+		length += listLength
+	case consts.OperatorBetween, consts.OperatorNotBetween:
+		rangeLength, err := s.buildRangeValue(attribute, operatorAttr, attrs[length:])
+		if err != nil {
+			return 0, nil, err
+		}
+		length += rangeLength
+	case consts.OperatorIsNull, consts.OperatorIsNotNull:
+	default:
+		if len(attrs) <= length || !s.isValue(attrs[length]) && !isSymbol(attrs[length], "(") {
+			return 0, nil, newSyntaxError(operatorAttr, consts.ErrorMessageMissingValue)
+		}
+		value := attrs[length]
+		isGroup := isSymbol(value, "(")
+		if isGroup || len(attrs) > length+1 && (isArithmeticOperator(attrs[length+1]) || isSymbol(attrs[length+1], "(")) {
+			expressionLength, expression, err := s.buildExpression(attrs[length:], false)
+			if err != nil {
+				return 0, nil, err
+			}
+			if (isGroup || isArithmetic(expression)) && !isComparisonOperator(operator) {
+				return 0, nil, newSyntaxError(operatorAttr, consts.ErrorMessageInvalidExpression)
+			}
+			if !isOperand(expression) {
+				attribute.Value, attribute.Right = formatExpression(expression), expression
+				length += expressionLength
+				break
+			}
+			// A grouped attribute, like (a) in x = (a), is a reference to it and a
+			// grouped literal is the literal itself.
+			value = &types.TokenAttribute{Value: expression.Value, Line: value.Line, Column: value.Column}
+			if expression.Name != "" {
+				value.Value = consts.ReferencePrefix + expression.Name
+			}
+			length += expressionLength - 1
+		} else if s.isUnquotedWords(attrs[length:]) {
+			return 0, nil, newSyntaxError(value, consts.ErrorMessageUnquotedValue)
+		}
+		attribute.Value, attribute.Type = getLiteral(value)
+		switch {
+		case attribute.Type == valuetype.Null && operator != consts.OperatorEqual && operator != consts.OperatorNotEqual:
+			return 0, nil, newSyntaxError(value, consts.ErrorMessageInvalidNullComparison)
+		case attribute.Type == valuetype.Reference && !isComparisonOperator(operator):
+			return 0, nil, newSyntaxError(value, consts.ErrorMessageInvalidReference)
+		case isArithmetic(attribute.Left) && attribute.Type != valuetype.Numeric &&
+			attribute.Type != valuetype.Reference && attribute.Type != valuetype.Parameter:
+			return 0, nil, newSyntaxError(value, consts.ErrorMessageInvalidOperand)
+		}
+		if operator == consts.OperatorRegex && attribute.Type != valuetype.Parameter {
+			if _, err := regexp.Compile(value.Value); err != nil {
+				return 0, nil, newSyntaxError(value, fmt.Sprintf(consts.ErrorMessageInvalidRegex, err))
+			}
+		}
+		length++
+	}
+	return length, attribute, nil
+}
+
+// buildListValue parses a parenthesized list of values, e.g. (1, 2, 3), into a
+// comma separated attribute value and returns the number of consumed tokens.
+func (s *StructGen) buildListValue(attribute *types.Attribute, operatorAttr *types.TokenAttribute, attrs []*types.TokenAttribute) (int, error) {
+	if len(attrs) == 0 || !isSymbol(attrs[0], "(") {
+		return 0, newSyntaxError(operatorAttr, consts.ErrorMessageExpectedList)
+	}
+	var (
+		values         []string
+		isAlphanumeric bool
+	)
+	previous := attrs[0]
+	for i := 1; i < len(attrs); i++ {
+		attr := attrs[i]
+		if !s.isValue(attr) {
+			return 0, newSyntaxError(previous, consts.ErrorMessageMissingValue)
+		}
+		values = append(values, attr.Value)
+		if err := types.CheckLimit(consts.LimitListSize, s.Limits.WithDefaults().MaxListSize, len(values)); err != nil {
+			return 0, err
+		}
+		isAlphanumeric = isAlphanumeric || attr.IsAlphanumeric
+		if i+1 >= len(attrs) {
+			break
+		}
+		separator := attrs[i+1]
+		if isSymbol(separator, ")") {
+			attribute.Value = strings.Join(values, ",")
+			attribute.Type = valuetype.Alphanumeric
+			if !isAlphanumeric {
+				attribute.Type = getValueType(attribute.Value)
 			}
-			buffer.WriteRune(char)
+			return i + 2, nil
+		}
+		if !isSymbol(separator, ",") {
+			return 0, newSyntaxError(separator, consts.ErrorMessageExpectedListSeparator)
+		}
+		previous = separator
+		i++
+	}
+	return 0, newSyntaxError(attrs[0], consts.ErrorMessageUnclosedParenthesis)
+}
+
+// buildRangeValue parses the bounds of a range, e.g. 100 AND 500, into a comma
+// separated attribute value and returns the number of consumed tokens. Both
+// bounds must be numbers or dates.
+func (s *StructGen) buildRangeValue(attribute *types.Attribute, operatorAttr *types.TokenAttribute, attrs []*types.TokenAttribute) (int, error) {
+	if len(attrs) == 0 || !s.isValue(attrs[0]) {
+		return 0, newSyntaxError(operatorAttr, consts.ErrorMessageMissingValue)
+	}
+	if len(attrs) < 2 || !isKeyword(attrs[1], consts.LogicalOperatorAnd) {
+		return 0, newSyntaxError(attrs[0], consts.ErrorMessageExpectedRangeSeparator)
+	}
+	if len(attrs) < 3 || !s.isValue(attrs[2]) {
+		return 0, newSyntaxError(attrs[1], consts.ErrorMessageMissingValue)
+	}
+	low, lowType := getRangeBound(attrs[0])
+	high, highType := getRangeBound(attrs[2])
+	if lowType == "" {
+		return 0, newSyntaxError(attrs[0], consts.ErrorMessageInvalidRange)
+	}
+	if highType != lowType {
+		return 0, newSyntaxError(attrs[2], consts.ErrorMessageInvalidRange)
+	}
+	attribute.Value, attribute.Type = low+","+high, lowType
+	return 3, nil
+}
+
+// getRangeBound returns the value of a range bound and its type, numeric or
+// date, or an empty type for any other value.
+func getRangeBound(attr *types.TokenAttribute) (string, valuetype.ValueType) {
+	value, valueType := getLiteral(attr)
+	switch valueType {
+	case valuetype.Numeric:
+		return value, valuetype.Numeric
+	case valuetype.Alphanumeric, valuetype.Date:
+		if _, err := utils.ParseTime(value); err == nil {
+			return value, valuetype.Date
+		}
+	}
+	return value, ""
+}
+
+// getOperator returns the comparison operator at the beginning of attrs and
+// the number of tokens it spans, or zero if there is none. Keyword operators
+// like NOT IN, and registered ones, are made of several words. Keyword
+// operators are matched case insensitively, registered ones as written.
+func (s *StructGen) getOperator(attrs []*types.TokenAttribute) (string, int) {
+	attr := attrs[0]
+	if attr.IsAlphanumeric {
+		return "", 0
+	}
+	if operator, ok := s.getDialect().Operators[attr.Value]; ok {
+		return operator, 1
+	}
+	for length := maxKeywordOperatorLength; length > 0; length-- {
+		if length > len(attrs) {
+			continue
+		}
+		words := make([]string, 0, length)
+		for _, word := range attrs[:length] {
+			if word.IsAlphanumeric {
+				break
+			}
+			words = append(words, word.Value)
+		}
+		keyword := strings.Join(words, " ")
+		if len(words) != length {
+			continue
+		}
+		if _, ok := keywordOperatorMap[strings.ToUpper(keyword)]; ok {
+			return strings.ToUpper(keyword), length
+		}
+		if _, ok := s.getRegistry().Operator(keyword); ok {
+			return keyword, length
+		}
+	}
+	return "", 0
+}
+
+func (s *StructGen) hasOperator(attrs []*types.TokenAttribute) bool {
+	_, length := s.getOperator(attrs)
+	return length > 0
+}
+
+// isPredicate reports whether expression calls a registered function returning
+// a boolean, which is a condition on its own, e.g. is_weekend(order_date).
+func (s *StructGen) isPredicate(expression *types.Expression) bool {
+	if expression == nil || expression.Function == "" {
+		return false
+	}
+	function, ok := s.getRegistry().Function(expression.Function)
+	return ok && function.ReturnType == valuetype.Boolean
+}
+
+// getTokenAttributes splits query into tokens within the length and token
+// limits.
+func (s *StructGen) getTokenAttributes(query string) ([]*types.TokenAttribute, error) {
+	limits := s.Limits.WithDefaults()
+	if err := types.CheckLimit(consts.LimitQueryLength, limits.MaxQueryLength, len(query)); err != nil {
+		return nil, err
+	}
+	tokenAttributes, err := s.getDialect().getTokenAttributes(query)
+	if err != nil {
+		return nil, err
+	}
+	if err := types.CheckLimit(consts.LimitTokens, limits.MaxTokens, len(tokenAttributes)); err != nil {
+		return nil, err
+	}
+	return tokenAttributes, nil
+}
+
+// enter goes one level deeper into a group, a quantifier or a function call,
+// failing beyond the depth limit. The caller goes back up with s.depth--.
+func (s *StructGen) enter() error {
+	s.depth++
+	if s.depth > s.peak {
+		s.peak = s.depth
+	}
+	return types.CheckLimit(consts.LimitDepth, s.Limits.WithDefaults().MaxDepth, s.depth)
+}
+
+func (s *StructGen) getDialect() *Dialect {
+	if s.dialect == nil {
+		return defaultDialect
+	}
+	return s.dialect
+}
+
+func (s *StructGen) getRegistry() *registry.Registry {
+	if s.Registry == nil {
+		return registry.Default
+	}
+	return s.Registry
+}
+
+// isUnquotedWords reports whether attrs starts with a value made of several
+// words, e.g. New York, rather than a value followed by the next comparison.
+func (s *StructGen) isUnquotedWords(attrs []*types.TokenAttribute) bool {
+	if len(attrs) < 2 || !s.isValue(attrs[1]) {
+		return false
+	}
+	if len(attrs) > 2 {
+		if _, length := s.getOperator(attrs[2:]); length > 0 {
+			return false
 		}
 	}
-	if buffer.Len() > 0 {
-		tokenAttributes = appendAttribute(tokenAttributes, buffer, buffer.String(), isAlphanumeric)
-		isAlphanumeric = false
+	return true
+}
+
+// isExpressionGroup reports whether the parenthesis at the beginning of attrs
+// groups the operands of an expression, e.g. (a + b) * c > 1, rather than
+// conditions, as it's followed by an arithmetic or a comparison operator.
+func (s *StructGen) isExpressionGroup(attrs []*types.TokenAttribute) bool {
+	depth := 0
+	for i, attr := range attrs {
+		switch {
+		case isSymbol(attr, "("):
+			depth++
+		case isSymbol(attr, ")"):
+			depth--
+			if depth == 0 {
+				return i+1 < len(attrs) && (isArithmeticOperator(attrs[i+1]) || s.hasOperator(attrs[i+1:]))
+			}
+		}
+	}
+	return false
+}
+
+// isQuantifier reports whether attrs starts with a quantifier call like
+// any( rather than a comparison.
+func isQuantifier(attrs []*types.TokenAttribute) bool {
+	if len(attrs) < 2 || attrs[0].IsAlphanumeric || !isSymbol(attrs[1], "(") {
+		return false
+	}
+	_, ok := quantifierMap[strings.ToLower(attrs[0].Value)]
+	return ok
+}
+
+func (s *StructGen) getLogicalOperator(attr *types.TokenAttribute) (string, bool) {
+	if attr.IsAlphanumeric {
+		return "", false
+	}
+	if val, ok := s.getDialect().LogicalOperators[attr.Value]; ok {
+		return val, true
+	}
+	val, ok := logicalKeywordMap[strings.ToUpper(attr.Value)]
+	return val, ok
+}
+
+// isNegation reports whether attr negates the following condition, written as
+// the negation symbol of the dialect or as the NOT keyword.
+func (s *StructGen) isNegation(attr *types.TokenAttribute) bool {
+	negation := s.getDialect().Negation
+	return negation != "" && isSymbol(attr, negation) || isKeyword(attr, consts.LogicalOperatorNot)
+}
+
+func isSymbol(attr *types.TokenAttribute, symbol string) bool {
+	return !attr.IsAlphanumeric && attr.Value == symbol
+}
+
+// isKeyword reports whether attr is the unquoted keyword, in any case. Words
+// merely containing it, like android, aren't.
+func isKeyword(attr *types.TokenAttribute, keyword string) bool {
+	return !attr.IsAlphanumeric && strings.EqualFold(attr.Value, keyword)
+}
+
+// isValue reports whether attr is an attribute name or a value rather than an
+// operator, a logical keyword or a parenthesis.
+func (s *StructGen) isValue(attr *types.TokenAttribute) bool {
+	if attr.IsAlphanumeric {
+		return true
+	}
+	if attr.Value == "" || attr.Value == "(" || attr.Value == ")" || attr.Value == "," {
+		return false
+	}
+	if _, ok := keywordOperatorMap[strings.ToUpper(attr.Value)]; ok {
+		return false
+	}
+	if _, ok := s.getLogicalOperator(attr); ok || isKeyword(attr, consts.LogicalOperatorNot) {
+		return false
+	}
+	if _, ok := arithmeticOperatorMap[attr.Value]; ok {
+		return false
+	}
+	return !s.isSymbolToken(attr)
+}
+
+// isSymbolToken reports whether attr was read as a run of symbols of the
+// dialect, rather than a word or a parameter like :member_id.
+func (s *StructGen) isSymbolToken(attr *types.TokenAttribute) bool {
+	if attr.IsAlphanumeric || attr.Value == "" || !s.getDialect().symbolRunes[[]rune(attr.Value)[0]] {
+		return false
+	}
+	return !isParameter(attr) || !isNameStart([]rune(strings.TrimPrefix(attr.Value, consts.ParameterPrefix))[0])
+}
+
+// isParameter reports whether attr is a named placeholder like :member_id.
+func isParameter(attr *types.TokenAttribute) bool {
+	return !attr.IsAlphanumeric && len(attr.Value) > len(consts.ParameterPrefix) &&
+		strings.HasPrefix(attr.Value, consts.ParameterPrefix)
+}
+
+func isComparisonOperator(operator string) bool {
+	switch operator {
+	case consts.OperatorEqual, consts.OperatorNotEqual,
+		consts.OperatorLessThan, consts.OperatorLessThanEqual,
+		consts.OperatorGreaterThan, consts.OperatorGreaterThanEqual:
+		return true
 	}
-	return tokenAttributes
+	return false
 }
 
-func appendAttribute(tokenAttributes []*types.TokenAttribute, buffer *bytes.Buffer, value string, isAlphanumeric bool) []*types.TokenAttribute {
-	tokenAttributes = append(tokenAttributes, &types.TokenAttribute{
-		Value:          value,
-		IsAlphanumeric: isAlphanumeric,
-	})
-	buffer.Reset()
-	return tokenAttributes
+func (s *StructGen) isUnknownOperator(attr *types.TokenAttribute) bool {
+	return s.isSymbolToken(attr) && !s.getDialect().isSymbolOperator(attr.Value)
+}
+
+// getForeignOperator returns a comparison operator of the default dialect at
+// the beginning of attrs that the dialect spells differently, along with the
+// symbol of the dialect for it, e.g. != and <> in a dialect using <> for
+// inequality. The operator can span several adjacent tokens, as the dialect
+// doesn't read it as one symbol.
+func (s *StructGen) getForeignOperator(attrs []*types.TokenAttribute) (string, string, bool) {
+	dialect := s.getDialect()
+	var text, foreign string
+	for i, attr := range attrs {
+		if attr.IsAlphanumeric {
+			break
+		}
+		if i > 0 {
+			previous := attrs[i-1]
+			if attr.Line != previous.Line || attr.Column != previous.Column+len([]rune(previous.Value)) {
+				break
+			}
+		}
+		text += attr.Value
+		if len([]rune(text)) > defaultDialect.symbolLength {
+			break
+		}
+		if _, ok := defaultDialect.Operators[text]; ok {
+			foreign = text
+		}
+	}
+	if _, ok := dialect.Operators[foreign]; foreign == "" || ok {
+		return "", "", false
+	}
+	symbol, ok := dialect.getOperatorSymbol(defaultDialect.Operators[foreign])
+	return foreign, symbol, ok
+}
+
+// getLiteral returns the value of attr and its type. Quoted values are always
+// strings, while the unquoted true, false and null keywords are normalized to
+// lower case. A $ prefixed value references another attribute by name and a
+// : prefixed value is a parameter bound later.
+func getLiteral(attr *types.TokenAttribute) (string, valuetype.ValueType) {
+	if attr.IsAlphanumeric {
+		return attr.Value, valuetype.Alphanumeric
+	}
+	if len(attr.Value) > len(consts.ReferencePrefix) && strings.HasPrefix(attr.Value, consts.ReferencePrefix) {
+		return strings.TrimPrefix(attr.Value, consts.ReferencePrefix), valuetype.Reference
+	}
+	if isParameter(attr) {
+		return strings.TrimPrefix(attr.Value, consts.ParameterPrefix), valuetype.Parameter
+	}
+	switch value := strings.ToLower(attr.Value); value {
+	case consts.LiteralTrue, consts.LiteralFalse:
+		return value, valuetype.Boolean
+	case consts.LiteralNull:
+		return value, valuetype.Null
+	}
+	return attr.Value, getValueType(attr.Value)
 }
 
 func getValueType(value string) valuetype.ValueType {
@@ -199,7 +768,7 @@ func getValueType(value string) valuetype.ValueType {
 		indexVal++
 	}
 	if varType == valuetype.Alphanumeric {
-		if _, err := time.Parse(consts.DateTimeFormat, value); err == nil {
+		if _, err := utils.ParseTime(value); err == nil {
 			varType = valuetype.Date
 		}
 	}
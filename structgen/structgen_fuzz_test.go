@@ -0,0 +1,150 @@
+package structgen
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/registry"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"testing"
+)
+
+var formatSeeds = []string{
+	`id = 1`,
+	`id=1 && member_id=2 && (division=engineering || division=finance)`,
+	`a = 1 || b = 2 && c = 3 || !(d = 4 && e = 5)`,
+	`(a = 1 || b = 2) && !c = 3`,
+	`((a = 1))`,
+	`name = "John Doe" && note = 'say "hi"' && path ~ "^\d+\\" && tier = gold`,
+	`status IN (1, 2, 3) && tier NOT IN ("gold", silver, "a,b")`,
+	`price BETWEEN 100 AND 500.5 && created_at NOT BETWEEN 2019-08-08 AND "2019-09-09 10:00:00"`,
+	`deleted_at IS NULL && updated_at = null && flag = TRUE && id IS NOT NULL`,
+	`price * quantity + fee > $budget && lower(name) = "john" && coalesce(nickname, name) != "x"`,
+	`created_at > now() - 7d && day(created_at) = 1 && member_id = :member_id && id IN :ids`,
+	`name LIKE "jo%" && name STARTS WITH "jo" && name ENDS WITH doe && name CONTAINS "and"`,
+	`any(items, sku = "A1" && qty > 2) || !all(items, price > 0) && none(tags, tags = "spam")`,
+	`sum(items.price) > 500000 && count(items) >= 3 || avg(ratings.score) < 2.5`,
+	`"true" = "null" && "IN" = "AND" && "a b" = 1 && x = "-5" && y = "7d"`,
+	`$7d + 1 > 2 && a = 2019-09-09T10:00:00+07:00`,
+	`status = paid and (channel = web OR channel = app) And NOT brand = android`,
+	`price between 1 and 5 and id not in (1, 2) or deleted_at is null`,
+	`"x-y" = 1 && user-agent = "x" && $"00%000>000&&000=000`,
+	``,
+}
+
+// FuzzFormat checks that formatting a parsed condition and parsing it again
+// gives the same condition, and that formatting is stable.
+func FuzzFormat(f *testing.F) {
+	for _, seed := range formatSeeds {
+		f.Add(seed)
+	}
+	s := StructGen{}
+	f.Fuzz(func(t *testing.T, query string) {
+		condition, err := s.GenerateCondition(query)
+		if err != nil {
+			return
+		}
+		formatted := Format(condition)
+		got, err := s.GenerateCondition(formatted)
+		if err != nil {
+			t.Fatalf("GenerateCondition(%q) of %q error = %v", formatted, query, err)
+		}
+		want, _ := json.Marshal(condition)
+		gotBytes, _ := json.Marshal(got)
+		if string(gotBytes) != string(want) {
+			t.Fatalf("GenerateCondition(%q) of %q = %s, want %s", formatted, query, gotBytes, want)
+		}
+		if reformatted := Format(got); reformatted != formatted {
+			t.Errorf("Format() = %q, want %q", reformatted, formatted)
+		}
+	})
+}
+
+// FuzzGetTokenAttributes checks that the tokenizer either fails with a syntax
+// error or returns tokens in the order they're written.
+func FuzzGetTokenAttributes(f *testing.F) {
+	for _, seed := range formatSeeds {
+		f.Add(seed)
+	}
+	f.Add("# comment\n/* block */ id = 1 // trailing")
+	f.Add(`name = "unterminated`)
+	f.Add("/* unterminated")
+	f.Fuzz(func(t *testing.T, query string) {
+		attrs, err := getTokenAttributes(query)
+		if err != nil {
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("getTokenAttributes(%q) error = %v, want a *SyntaxError", query, err)
+			}
+			return
+		}
+		line, column := 1, 0
+		for _, attr := range attrs {
+			if attr.Line < line || attr.Line == line && attr.Column <= column {
+				t.Fatalf("getTokenAttributes(%q) token %q at %d:%d after %d:%d", query, attr.Value, attr.Line, attr.Column, line, column)
+			}
+			line, column = attr.Line, attr.Column
+		}
+	})
+}
+
+// FuzzBuildCondition checks that the parser, in the default dialect and in one
+// with more symbols, either fails with a syntax error or a limit error, or
+// builds a condition within its limits.
+func FuzzBuildCondition(f *testing.F) {
+	for _, seed := range formatSeeds {
+		f.Add(seed)
+	}
+	f.Add(`@tech_divisions && !@loop`)
+	f.Add(`((((((id = 1))))))`)
+	f.Add(`lower(trim(upper(lower(name)))) = x`)
+	f.Add(`id IN (1, 2, 3, 4, 5, 6, 7, 8, 9)`)
+	r := registry.New()
+	conditions := map[string]string{
+		"tech_divisions": "division=engineering || division=finance",
+		"loop":           "id = 1 && @loop",
+	}
+	for name, query := range conditions {
+		if err := r.RegisterCondition(name, query); err != nil {
+			f.Fatalf("RegisterCondition() error = %v", err)
+		}
+	}
+	f.Add(`status:paid & member_id == :member_id | id <> 1`)
+	dialect := DefaultDialect()
+	dialect.Operators[":"] = consts.OperatorEqual
+	dialect.Operators["=="] = consts.OperatorEqual
+	dialect.Operators["<>"] = consts.OperatorNotEqual
+	dialect.LogicalOperators["&"] = consts.LogicalOperatorAnd
+	dialect.LogicalOperators["|"] = consts.LogicalOperatorOr
+	colon, err := New(dialect)
+	if err != nil {
+		f.Fatalf("New() error = %v", err)
+	}
+	limits := types.Limits{MaxDepth: 4, MaxTokens: 64, MaxListSize: 8, MaxQueryLength: 256}
+	f.Fuzz(func(t *testing.T, query string) {
+		for _, s := range []StructGen{{}, *colon} {
+			s.Registry, s.Limits = r, limits
+			attrs, err := s.getDialect().getTokenAttributes(query)
+			if err != nil || len(attrs) == 0 {
+				continue
+			}
+			_, condition, err := s.buildCondition(types.Condition{}, attrs, nil)
+			if err != nil {
+				var (
+					syntaxErr *SyntaxError
+					limitErr  *types.LimitError
+				)
+				if !errors.As(err, &syntaxErr) && !errors.As(err, &limitErr) {
+					t.Fatalf("buildCondition(%q) error = %v, want a *SyntaxError or a *types.LimitError", query, err)
+				}
+				continue
+			}
+			if s.depth != 0 {
+				t.Fatalf("buildCondition(%q) depth = %d, want 0", query, s.depth)
+			}
+			if err := (types.Limits{MaxDepth: -1, MaxTokens: -1, MaxListSize: limits.MaxListSize, MaxQueryLength: -1}).CheckCondition(&condition); err != nil {
+				t.Fatalf("buildCondition(%q) = condition beyond its limits: %v", query, err)
+			}
+		}
+	})
+}
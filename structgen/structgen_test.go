@@ -3,10 +3,16 @@ package structgen
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/registry"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestGenerateConditionQueryStructure(t *testing.T) {
@@ -46,7 +52,7 @@ func TestGenerateConditionQueryStructure(t *testing.T) {
                       ) && user_id = 43
 `,
 			},
-			want:    `{"conditions":[{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2","type":"numeric"}}]},{"operator":"OR","conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance","type":"alphanumeric"}}]},{"operator":"AND","attribute":{"name":"user_id","operator":"=","value":"43","type":"numeric"}}]}`,
+			want:    `{"conditions":[{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2","type":"numeric"}}]},{"operator":"OR","conditions":[{"conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance","type":"alphanumeric"}}]},{"operator":"AND","attribute":{"name":"user_id","operator":"=","value":"43","type":"numeric"}}]}]}`,
 			wantErr: false,
 		},
 		{
@@ -118,7 +124,212 @@ func TestGenerateConditionQueryStructure(t *testing.T) {
 			args: args{
 				query: "((date<=2019-09-09 && date > 2019-08-08) || (p_date>=2019-01-01 && p_date<2019-02-02)) && (member_type=1||member_type=2)",
 			},
-			want:    `{"conditions":[{"conditions":[{"conditions":[{"attribute":{"name":"date","operator":"\u003c=","value":"2019-09-09","type":"alphanumeric"}},{"operator":"AND","attribute":{"name":"date","operator":"\u003e","value":"2019-08-08","type":"alphanumeric"}}]},{"operator":"OR","conditions":[{"attribute":{"name":"p_date","operator":"\u003e=","value":"2019-01-01","type":"alphanumeric"}},{"operator":"AND","attribute":{"name":"p_date","operator":"\u003c","value":"2019-02-02","type":"alphanumeric"}}]}]},{"operator":"AND","conditions":[{"attribute":{"name":"member_type","operator":"=","value":"1","type":"numeric"}},{"operator":"OR","attribute":{"name":"member_type","operator":"=","value":"2","type":"numeric"}}]}]}`,
+			want:    `{"conditions":[{"conditions":[{"conditions":[{"attribute":{"name":"date","operator":"\u003c=","value":"2019-09-09","type":"date"}},{"operator":"AND","attribute":{"name":"date","operator":"\u003e","value":"2019-08-08","type":"date"}}]},{"operator":"OR","conditions":[{"attribute":{"name":"p_date","operator":"\u003e=","value":"2019-01-01","type":"date"}},{"operator":"AND","attribute":{"name":"p_date","operator":"\u003c","value":"2019-02-02","type":"date"}}]}]},{"operator":"AND","conditions":[{"attribute":{"name":"member_type","operator":"=","value":"1","type":"numeric"}},{"operator":"OR","attribute":{"name":"member_type","operator":"=","value":"2","type":"numeric"}}]}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - not equal, include and exclude operator",
+			args: args{
+				query: `id != 1 && member_id IN (1, 2,3) && (division NOT IN ("people", finance) || level IN ("1"))`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"id","operator":"!=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"member_id","operator":"IN","value":"1,2,3","type":"numeric"}},{"operator":"AND","conditions":[{"attribute":{"name":"division","operator":"NOT IN","value":"people,finance","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"level","operator":"IN","value":"1","type":"alphanumeric"}}]}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Error case - include operator without list",
+			args: args{
+				query: `id IN 1`,
+			},
+			want:    `{}`,
+			wantErr: true,
+		},
+		{
+			name: "Error case - include operator with trailing comma",
+			args: args{
+				query: `id IN (1,)`,
+			},
+			want:    `{}`,
+			wantErr: true,
+		},
+		{
+			name: "Normal case - null operator",
+			args: args{
+				query: `deleted_at IS NULL && (coupon IS NOT NULL || id = 1)`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"deleted_at","operator":"IS NULL","value":""}},{"operator":"AND","conditions":[{"attribute":{"name":"coupon","operator":"IS NOT NULL","value":""}},{"operator":"OR","attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}}]}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - string pattern operator",
+			args: args{
+				query: `name LIKE "PRM-%" || (code STARTS WITH 12 && city ENDS WITH karta && note CONTAINS "50%")`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"name","operator":"LIKE","value":"PRM-%","type":"alphanumeric"}},{"operator":"OR","conditions":[{"attribute":{"name":"code","operator":"STARTS WITH","value":"12","type":"numeric"}},{"operator":"AND","attribute":{"name":"city","operator":"ENDS WITH","value":"karta","type":"alphanumeric"}},{"operator":"AND","attribute":{"name":"note","operator":"CONTAINS","value":"50%","type":"alphanumeric"}}]}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - regex operator",
+			args: args{
+				query: `name ~ "^PRM-[0-9]+$" || code~^12`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"name","operator":"~","value":"^PRM-[0-9]+$","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"code","operator":"~","value":"^12","type":"alphanumeric"}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - negated condition",
+			args: args{
+				query: `!(division=engineering || division=finance) && !id=1 && !!member_id=2`,
+			},
+			want:    `{"conditions":[{"negate":true,"conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance","type":"alphanumeric"}}]},{"operator":"AND","negate":true,"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2","type":"numeric"}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - and takes precedence over or",
+			args: args{
+				query: `a=1 || b=2 && c=3 || !d=4 && (e=5 || f=6 && g=7)`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"a","operator":"=","value":"1","type":"numeric"}},{"operator":"OR","conditions":[{"attribute":{"name":"b","operator":"=","value":"2","type":"numeric"}},{"operator":"AND","attribute":{"name":"c","operator":"=","value":"3","type":"numeric"}}]},{"operator":"OR","conditions":[{"negate":true,"attribute":{"name":"d","operator":"=","value":"4","type":"numeric"}},{"operator":"AND","conditions":[{"attribute":{"name":"e","operator":"=","value":"5","type":"numeric"}},{"operator":"OR","conditions":[{"attribute":{"name":"f","operator":"=","value":"6","type":"numeric"}},{"operator":"AND","attribute":{"name":"g","operator":"=","value":"7","type":"numeric"}}]}]}]}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - typed literal",
+			args: args{
+				query: `is_active = TRUE && deleted_at = null && (code = "123" || flag != false)`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"is_active","operator":"=","value":"true","type":"boolean"}},{"operator":"AND","attribute":{"name":"deleted_at","operator":"=","value":"null","type":"null"}},{"operator":"AND","conditions":[{"attribute":{"name":"code","operator":"=","value":"123","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"flag","operator":"!=","value":"false","type":"boolean"}}]}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - attribute reference",
+			args: args{
+				query: `shipped_at > $ordered_at && (used_quota < $max_quota || code = "$code")`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"shipped_at","operator":"\u003e","value":"ordered_at","type":"reference"}},{"operator":"AND","conditions":[{"attribute":{"name":"used_quota","operator":"\u003c","value":"max_quota","type":"reference"}},{"operator":"OR","attribute":{"name":"code","operator":"=","value":"$code","type":"alphanumeric"}}]}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - parameter",
+			args: args{
+				query: `member_id = :member_id && created_at >= :since && status NOT IN :statuses`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"member_id","operator":"=","value":"member_id","type":"parameter"}},{"operator":"AND","attribute":{"name":"created_at","operator":"\u003e=","value":"since","type":"parameter"}},{"operator":"AND","attribute":{"name":"status","operator":"NOT IN","value":"statuses","type":"parameter"}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - arithmetic expression",
+			args: args{
+				query: `price * quantity > 1000000 && stock - reserved <= 5 && total >= price * 2 + fee % 3 - $discount`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"price * quantity","operator":"\u003e","value":"1000000","type":"numeric","left":{"operator":"*","left":{"name":"price"},"right":{"name":"quantity"}}}},{"operator":"AND","attribute":{"name":"stock - reserved","operator":"\u003c=","value":"5","type":"numeric","left":{"operator":"-","left":{"name":"stock"},"right":{"name":"reserved"}}}},{"operator":"AND","attribute":{"name":"total","operator":"\u003e=","value":"price * 2 + fee % 3 - discount","right":{"operator":"-","left":{"operator":"+","left":{"operator":"*","left":{"name":"price"},"right":{"value":"2"}},"right":{"operator":"%","left":{"name":"fee"},"right":{"value":"3"}}},"right":{"name":"discount"}}}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - function call",
+			args: args{
+				query: `lower(email) = "x@y.com" && len(tags) > 2 && (YEAR(created_at) = 2020 || coalesce(nickname, name, "anon") = budi)`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"lower(email)","operator":"=","value":"x@y.com","type":"alphanumeric","left":{"function":"lower","arguments":[{"name":"email"}]}}},{"operator":"AND","attribute":{"name":"len(tags)","operator":"\u003e","value":"2","type":"numeric","left":{"function":"len","arguments":[{"name":"tags"}]}}},{"operator":"AND","conditions":[{"attribute":{"name":"year(created_at)","operator":"=","value":"2020","type":"numeric","left":{"function":"year","arguments":[{"name":"created_at"}]}}},{"operator":"OR","attribute":{"name":"coalesce(nickname, name, \"anon\")","operator":"=","value":"budi","type":"alphanumeric","left":{"function":"coalesce","arguments":[{"name":"nickname"},{"name":"name"},{"value":"anon","type":"alphanumeric"}]}}}]}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - function call in expression",
+			args: args{
+				query: `name = upper(nickname) || abs(balance - 10) * 2 < 5`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"name","operator":"=","value":"upper(nickname)","right":{"function":"upper","arguments":[{"name":"nickname"}]}}},{"operator":"OR","attribute":{"name":"abs(balance - 10) * 2","operator":"\u003c","value":"5","type":"numeric","left":{"operator":"*","left":{"function":"abs","arguments":[{"operator":"-","left":{"name":"balance"},"right":{"value":"10"}}]},"right":{"value":"2"}}}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - range",
+			args: args{
+				query: `price BETWEEN 100 AND 500.5 && created_at NOT BETWEEN "2019-08-08 00:00:00" AND "2019-09-09 00:00:00"`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"price","operator":"BETWEEN","value":"100,500.5","type":"numeric"}},{"operator":"AND","attribute":{"name":"created_at","operator":"NOT BETWEEN","value":"2019-08-08 00:00:00,2019-09-09 00:00:00","type":"date"}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - relative date",
+			args: args{
+				query: `registered_at >= now() - 7d && created_at < today() + 12h || created_at >= startOfMonth()`,
+			},
+			want:    `{"conditions":[{"conditions":[{"attribute":{"name":"registered_at","operator":"\u003e=","value":"now() - 7d","right":{"operator":"-","left":{"function":"now"},"right":{"value":"7d","type":"duration"}}}},{"operator":"AND","attribute":{"name":"created_at","operator":"\u003c","value":"today() + 12h","right":{"operator":"+","left":{"function":"today"},"right":{"value":"12h","type":"duration"}}}}]},{"operator":"OR","attribute":{"name":"created_at","operator":"\u003e=","value":"startofmonth()","right":{"function":"startofmonth"}}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - date formats",
+			args: args{
+				query: `created_at >= 2019-09-09 && updated_at < 2019-09-09T10:00:00+07:00 && deleted_at = 1568000000`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"created_at","operator":"\u003e=","value":"2019-09-09","type":"date"}},{"operator":"AND","attribute":{"name":"updated_at","operator":"\u003c","value":"2019-09-09T10:00:00+07:00","type":"date"}},{"operator":"AND","attribute":{"name":"deleted_at","operator":"=","value":"1568000000","type":"numeric"}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - quantifiers",
+			args: args{
+				query: `id = 1 && any(items, sku = "A1" && qty > 2) || !ALL(items, price > 0) && none(tags, tags = "spam")`,
+			},
+			want:    `{"conditions":[{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","quantifier":{"operator":"any","name":"items","condition":{"conditions":[{"attribute":{"name":"sku","operator":"=","value":"A1","type":"alphanumeric"}},{"operator":"AND","attribute":{"name":"qty","operator":"\u003e","value":"2","type":"numeric"}}]}}}]},{"operator":"OR","conditions":[{"negate":true,"quantifier":{"operator":"all","name":"items","condition":{"conditions":[{"attribute":{"name":"price","operator":"\u003e","value":"0","type":"numeric"}}]}}},{"operator":"AND","quantifier":{"operator":"none","name":"tags","condition":{"conditions":[{"attribute":{"name":"tags","operator":"=","value":"spam","type":"alphanumeric"}}]}}}]}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - aggregates",
+			args: args{
+				query: `sum(items.price) > 500000 && COUNT(items) >= 3 || avg(ratings.score) < 2.5`,
+			},
+			want:    `{"conditions":[{"conditions":[{"attribute":{"name":"sum(items.price)","operator":"\u003e","value":"500000","type":"numeric","left":{"aggregate":"sum","name":"items.price"}}},{"operator":"AND","attribute":{"name":"count(items)","operator":"\u003e=","value":"3","type":"numeric","left":{"aggregate":"count","name":"items"}}}]},{"operator":"OR","attribute":{"name":"avg(ratings.score)","operator":"\u003c","value":"2.5","type":"numeric","left":{"aggregate":"avg","name":"ratings.score"}}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - logical keywords",
+			args: args{
+				query: `status = paid and (channel = web OR channel = app) And NOT brand = android`,
+			},
+			want: `{"conditions":[{"attribute":{"name":"status","operator":"=","value":"paid","type":"alphanumeric"}},{"operator":"AND","conditions":[{"attribute":{"name":"channel","operator":"=","value":"web","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"channel","operator":"=","value":"app","type":"alphanumeric"}}]},{"operator":"AND","negate":true,"attribute":{"name":"brand","operator":"=","value":"android","type":"alphanumeric"}}]}`,
+		},
+		{
+			name: "Normal case - lower case keyword operators",
+			args: args{
+				query: `price between 1 and 5 and id not in (1, 2) or deleted_at is null`,
+			},
+			want: `{"conditions":[{"conditions":[{"attribute":{"name":"price","operator":"BETWEEN","value":"1,5","type":"numeric"}},{"operator":"AND","attribute":{"name":"id","operator":"NOT IN","value":"1,2","type":"numeric"}}]},{"operator":"OR","attribute":{"name":"deleted_at","operator":"IS NULL","value":""}}]}`,
+		},
+		{
+			name: "Normal case - quoted keywords",
+			args: args{
+				query: `"and" = 1 and "or" = "not"`,
+			},
+			want: `{"conditions":[{"attribute":{"name":"and","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"or","operator":"=","value":"not","type":"alphanumeric"}}]}`,
+		},
+		{
+			name: "Normal case - arithmetic expression without spaces",
+			args: args{
+				query: `price*quantity>1000000 && a+1>2 && total>=price*2+fee%3-$discount`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"price * quantity","operator":"\u003e","value":"1000000","type":"numeric","left":{"operator":"*","left":{"name":"price"},"right":{"name":"quantity"}}}},{"operator":"AND","attribute":{"name":"a + 1","operator":"\u003e","value":"2","type":"numeric","left":{"operator":"+","left":{"name":"a"},"right":{"value":"1"}}}},{"operator":"AND","attribute":{"name":"total","operator":"\u003e=","value":"price * 2 + fee % 3 - discount","right":{"operator":"-","left":{"operator":"+","left":{"operator":"*","left":{"name":"price"},"right":{"value":"2"}},"right":{"operator":"%","left":{"name":"fee"},"right":{"value":"3"}}},"right":{"name":"discount"}}}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - grouped arithmetic expression",
+			args: args{
+				query: `(a + b) * c > 1 && a - (b) > 1 && (x = 1 || total <= (price - discount) * 2)`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"(a + b) * c","operator":"\u003e","value":"1","type":"numeric","left":{"operator":"*","left":{"operator":"+","left":{"name":"a"},"right":{"name":"b"}},"right":{"name":"c"}}}},{"operator":"AND","attribute":{"name":"a - b","operator":"\u003e","value":"1","type":"numeric","left":{"operator":"-","left":{"name":"a"},"right":{"name":"b"}}}},{"operator":"AND","conditions":[{"attribute":{"name":"x","operator":"=","value":"1","type":"numeric"}},{"operator":"OR","attribute":{"name":"total","operator":"\u003c=","value":"(price - discount) * 2","right":{"operator":"*","left":{"operator":"-","left":{"name":"price"},"right":{"name":"discount"}},"right":{"value":"2"}}}}]}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - hyphenated attribute names",
+			args: args{
+				query: `x-y = 1 && user-agent = "x" && stock-reserved<=5`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"x-y","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"user-agent","operator":"=","value":"x","type":"alphanumeric"}},{"operator":"AND","attribute":{"name":"stock-reserved","operator":"\u003c=","value":"5","type":"numeric"}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - grouped single operand",
+			args: args{
+				query: `(a) = 1 && x = (b) && y >= ((5))`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"a","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"x","operator":"=","value":"b","type":"reference"}},{"operator":"AND","attribute":{"name":"y","operator":"\u003e=","value":"5","type":"numeric"}}]}`,
 			wantErr: false,
 		},
 	}
@@ -138,6 +349,270 @@ func TestGenerateConditionQueryStructure(t *testing.T) {
 	}
 }
 
+func TestGenerateConditionSyntaxError(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  SyntaxError
+	}{
+		{
+			name:  "Error case - double operator",
+			query: `id = = 1 &&`,
+			want:  SyntaxError{Line: 1, Column: 4, Token: "=", Message: consts.ErrorMessageMissingValue},
+		},
+		{
+			name:  "Error case - unclosed parenthesis",
+			query: `(a=1`,
+			want:  SyntaxError{Line: 1, Column: 1, Token: "(", Message: consts.ErrorMessageUnclosedParenthesis},
+		},
+		{
+			name:  "Error case - unbalanced parenthesis",
+			query: `a=1)`,
+			want:  SyntaxError{Line: 1, Column: 4, Token: ")", Message: consts.ErrorMessageUnbalancedParenthesis},
+		},
+		{
+			name:  "Error case - empty group",
+			query: `a=1 && ()`,
+			want:  SyntaxError{Line: 1, Column: 8, Token: "(", Message: consts.ErrorMessageEmptyGroup},
+		},
+		{
+			name:  "Error case - double logical operator",
+			query: `a=1 || || b=2`,
+			want:  SyntaxError{Line: 1, Column: 5, Token: "||", Message: consts.ErrorMessageMissingCondition},
+		},
+		{
+			name: "Error case - dangling logical operator",
+			query: `
+                a=1 &&
+                (b=2 ||)`,
+			want: SyntaxError{Line: 3, Column: 22, Token: "||", Message: consts.ErrorMessageMissingCondition},
+		},
+		{
+			name:  "Error case - leading logical operator",
+			query: `&& a=1`,
+			want:  SyntaxError{Line: 1, Column: 1, Token: "&&", Message: consts.ErrorMessageExpectedAttribute},
+		},
+		{
+			name:  "Error case - missing value",
+			query: `a=1 && b=`,
+			want:  SyntaxError{Line: 1, Column: 9, Token: "=", Message: consts.ErrorMessageMissingValue},
+		},
+		{
+			name:  "Error case - missing operator",
+			query: `a=1 && b`,
+			want:  SyntaxError{Line: 1, Column: 8, Token: "b", Message: consts.ErrorMessageMissingOperator},
+		},
+		{
+			name:  "Error case - reversed operator",
+			query: `a=1 && b =< 2`,
+			want:  SyntaxError{Line: 1, Column: 10, Token: "=", Message: consts.ErrorMessageMissingValue},
+		},
+		{
+			name:  "Error case - unknown comparison operator",
+			query: `a=1 && b |& 2`,
+			want:  SyntaxError{Line: 1, Column: 10, Token: "|&", Message: consts.ErrorMessageUnknownOperator},
+		},
+		{
+			name:  "Error case - unknown logical operator",
+			query: `a=1 & b=2`,
+			want:  SyntaxError{Line: 1, Column: 5, Token: "&", Message: consts.ErrorMessageUnknownOperator},
+		},
+		{
+			name:  "Error case - missing logical operator",
+			query: `a=1 b=2`,
+			want:  SyntaxError{Line: 1, Column: 5, Token: "b", Message: consts.ErrorMessageExpectedLogicalOperator},
+		},
+		{
+			name:  "Error case - unterminated quote",
+			query: `a="abc`,
+			want:  SyntaxError{Line: 1, Column: 3, Token: `"`, Message: consts.ErrorMessageUnterminatedQuote},
+		},
+		{
+			name:  "Error case - unclosed list",
+			query: `id NOT IN (1, 2 && a=1`,
+			want:  SyntaxError{Line: 1, Column: 17, Token: "&&", Message: consts.ErrorMessageExpectedListSeparator},
+		},
+		{
+			name:  "Error case - missing list value",
+			query: `id IN (1,,2)`,
+			want:  SyntaxError{Line: 1, Column: 9, Token: ",", Message: consts.ErrorMessageMissingValue},
+		},
+		{
+			name:  "Error case - invalid regular expression",
+			query: `name ~ "PRM-[0-9"`,
+			want:  SyntaxError{Line: 1, Column: 8, Token: "PRM-[0-9", Message: "invalid regular expression: error parsing regexp: missing closing ]: `[0-9`"},
+		},
+		{
+			name:  "Error case - dangling negation",
+			query: `a=1 && !`,
+			want:  SyntaxError{Line: 1, Column: 8, Token: "!", Message: consts.ErrorMessageMissingCondition},
+		},
+		{
+			name:  "Error case - negation in place of logical operator",
+			query: `a=1 !(b=2)`,
+			want:  SyntaxError{Line: 1, Column: 5, Token: "!", Message: consts.ErrorMessageExpectedLogicalOperator},
+		},
+		{
+			name:  "Error case - unquoted value with whitespace",
+			query: `id=1 && city = New York`,
+			want:  SyntaxError{Line: 1, Column: 16, Token: "New", Message: consts.ErrorMessageUnquotedValue},
+		},
+		{
+			name:  "Error case - unterminated single quote",
+			query: `name='O\'Brien`,
+			want:  SyntaxError{Line: 1, Column: 6, Token: `'`, Message: consts.ErrorMessageUnterminatedQuote},
+		},
+		{
+			name:  "Error case - ordering comparison with null",
+			query: `id=1 && deleted_at > null`,
+			want:  SyntaxError{Line: 1, Column: 22, Token: "null", Message: consts.ErrorMessageInvalidNullComparison},
+		},
+		{
+			name:  "Error case - pattern match with reference",
+			query: `id=1 && name LIKE $nickname`,
+			want:  SyntaxError{Line: 1, Column: 19, Token: "$nickname", Message: consts.ErrorMessageInvalidReference},
+		},
+		{
+			name:  "Error case - missing arithmetic operand",
+			query: `price * > 5`,
+			want:  SyntaxError{Line: 1, Column: 7, Token: "*", Message: consts.ErrorMessageMissingOperand},
+		},
+		{
+			name:  "Error case - pattern match with arithmetic expression",
+			query: `price * quantity LIKE 5`,
+			want:  SyntaxError{Line: 1, Column: 18, Token: "LIKE", Message: consts.ErrorMessageInvalidExpression},
+		},
+		{
+			name:  "Error case - string compared with arithmetic expression",
+			query: `price * 2 > abc`,
+			want:  SyntaxError{Line: 1, Column: 13, Token: "abc", Message: consts.ErrorMessageInvalidOperand},
+		},
+		{
+			name:  "Error case - quoted arithmetic operand",
+			query: `a = 1 && price * "2" > 1`,
+			want:  SyntaxError{Line: 1, Column: 18, Token: "2", Message: consts.ErrorMessageInvalidOperand},
+		},
+		{
+			name:  "Error case - unknown function",
+			query: `id=1 && foo(name) = 1`,
+			want:  SyntaxError{Line: 1, Column: 9, Token: "foo", Message: consts.ErrorMessageUnknownFunction},
+		},
+		{
+			name:  "Error case - wrong number of function arguments",
+			query: `lower(name, email) = "x"`,
+			want:  SyntaxError{Line: 1, Column: 1, Token: "lower", Message: fmt.Sprintf(consts.ErrorMessageFunctionArguments, "lower")},
+		},
+		{
+			name:  "Error case - missing function argument",
+			query: `coalesce(nickname,) = "x"`,
+			want:  SyntaxError{Line: 1, Column: 18, Token: ",", Message: consts.ErrorMessageMissingArgument},
+		},
+		{
+			name:  "Error case - unclosed function call",
+			query: `lower(name`,
+			want:  SyntaxError{Line: 1, Column: 6, Token: "(", Message: consts.ErrorMessageUnclosedParenthesis},
+		},
+		{
+			name:  "Error case - range without AND",
+			query: `price BETWEEN 100 500`,
+			want:  SyntaxError{Line: 1, Column: 15, Token: "100", Message: consts.ErrorMessageExpectedRangeSeparator},
+		},
+		{
+			name:  "Error case - range with missing bound",
+			query: `price BETWEEN 100 AND`,
+			want:  SyntaxError{Line: 1, Column: 19, Token: "AND", Message: consts.ErrorMessageMissingValue},
+		},
+		{
+			name:  "Error case - range with mixed bounds",
+			query: `price BETWEEN 100 AND "2019-08-08 00:00:00"`,
+			want:  SyntaxError{Line: 1, Column: 23, Token: "2019-08-08 00:00:00", Message: consts.ErrorMessageInvalidRange},
+		},
+		{
+			name:  "Error case - range of strings",
+			query: `name BETWEEN "a" AND "b"`,
+			want:  SyntaxError{Line: 1, Column: 14, Token: "a", Message: consts.ErrorMessageInvalidRange},
+		},
+		{
+			name:  "Error case - arguments for current date",
+			query: `created_at >= now(1)`,
+			want:  SyntaxError{Line: 1, Column: 15, Token: "now", Message: fmt.Sprintf(consts.ErrorMessageFunctionArguments, "now")},
+		},
+		{
+			name:  "Error case - quantifier without condition",
+			query: `any(items)`,
+			want:  SyntaxError{Line: 1, Column: 5, Token: "items", Message: "expected comma and condition after quantified attribute"},
+		},
+		{
+			name:  "Error case - quantifier without attribute",
+			query: `any("items", sku = "A1")`,
+			want:  SyntaxError{Line: 1, Column: 4, Token: "(", Message: "expected attribute name"},
+		},
+		{
+			name:  "Error case - aggregate without attribute",
+			query: `sum() > 1`,
+			want:  SyntaxError{Line: 1, Column: 4, Token: "(", Message: "missing function argument"},
+		},
+		{
+			name:  "Error case - aggregate of several attributes",
+			query: `sum(items.price, items.qty) > 1`,
+			want:  SyntaxError{Line: 1, Column: 1, Token: "sum", Message: "wrong number of arguments for function sum"},
+		},
+		{
+			name:  "Error case - unterminated block comment",
+			query: "id = 1 /* note\n&& tier = gold",
+			want:  SyntaxError{Line: 1, Column: 8, Token: "/*", Message: "unterminated block comment"},
+		},
+		{
+			name:  "Error case - logical keyword as value",
+			query: `brand = and`,
+			want:  SyntaxError{Line: 1, Column: 7, Token: "=", Message: consts.ErrorMessageMissingValue},
+		},
+		{
+			name:  "Error case - keyword operator as value",
+			query: `country = in`,
+			want:  SyntaxError{Line: 1, Column: 9, Token: "=", Message: consts.ErrorMessageMissingValue},
+		},
+		{
+			name:  "Error case - trailing logical keyword",
+			query: `brand = android or`,
+			want:  SyntaxError{Line: 1, Column: 17, Token: "or", Message: consts.ErrorMessageMissingCondition},
+		},
+		{
+			name:  "Error case - unclosed arithmetic group",
+			query: `total > (price - discount * 2`,
+			want:  SyntaxError{Line: 1, Column: 9, Token: "(", Message: consts.ErrorMessageUnclosedParenthesis},
+		},
+		{
+			name:  "Error case - multiplied date",
+			query: `id=1 && a > now() * 2`,
+			want:  SyntaxError{Line: 1, Column: 19, Token: "*", Message: consts.ErrorMessageInvalidDateArithmetic},
+		},
+		{
+			name:  "Error case - divided duration",
+			query: `a > 2 * (now() - 7d) && b < 7d / 2`,
+			want:  SyntaxError{Line: 1, Column: 7, Token: "*", Message: consts.ErrorMessageInvalidDateArithmetic},
+		},
+		{
+			name:  "Error case - pattern match with grouped value",
+			query: `name ~ (0)`,
+			want:  SyntaxError{Line: 1, Column: 6, Token: "~", Message: consts.ErrorMessageInvalidExpression},
+		},
+	}
+	s := StructGen{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.GenerateCondition(tt.query)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("GenerateCondition() error = %v, want %v", err, &tt.want)
+			}
+			if *syntaxErr != tt.want {
+				t.Errorf("GenerateCondition() error = %v, want %v", syntaxErr, &tt.want)
+			}
+		})
+	}
+}
+
 func Test_getToken(t *testing.T) {
 	type args struct {
 		value string
@@ -154,55 +629,89 @@ func Test_getToken(t *testing.T) {
 			},
 			want: []*types.TokenAttribute{
 				{
-					Value: "id",
+					Value:  "id",
+					Line:   1,
+					Column: 1,
 				},
 				{
-					Value: "=",
+					Value:  "=",
+					Line:   1,
+					Column: 3,
 				},
 				{
-					Value: "1",
+					Value:  "1",
+					Line:   1,
+					Column: 4,
 				},
 				{
-					Value: "&&",
+					Value:  "&&",
+					Line:   1,
+					Column: 6,
 				},
 				{
-					Value: "member_id",
+					Value:  "member_id",
+					Line:   1,
+					Column: 10,
 				},
 				{
-					Value: "=",
+					Value:  "=",
+					Line:   1,
+					Column: 19,
 				},
 				{
-					Value: "2",
+					Value:  "2",
+					Line:   1,
+					Column: 20,
 				},
 				{
-					Value: "&&",
+					Value:  "&&",
+					Line:   1,
+					Column: 24,
 				},
 				{
-					Value: "(",
+					Value:  "(",
+					Line:   1,
+					Column: 29,
 				},
 				{
-					Value: "division",
+					Value:  "division",
+					Line:   1,
+					Column: 30,
 				},
 				{
-					Value: "=",
+					Value:  "=",
+					Line:   1,
+					Column: 38,
 				},
 				{
-					Value: "engineering",
+					Value:  "engineering",
+					Line:   1,
+					Column: 39,
 				},
 				{
-					Value: "||",
+					Value:  "||",
+					Line:   1,
+					Column: 56,
 				},
 				{
-					Value: "division",
+					Value:  "division",
+					Line:   1,
+					Column: 59,
 				},
 				{
-					Value: "=",
+					Value:  "=",
+					Line:   1,
+					Column: 67,
 				},
 				{
-					Value: "finance",
+					Value:  "finance",
+					Line:   1,
+					Column: 68,
 				},
 				{
-					Value: ")",
+					Value:  ")",
+					Line:   1,
+					Column: 75,
 				},
 			},
 		},
@@ -213,55 +722,89 @@ func Test_getToken(t *testing.T) {
 			},
 			want: []*types.TokenAttribute{
 				{
-					Value: "id",
+					Value:  "id",
+					Line:   1,
+					Column: 1,
 				},
 				{
-					Value: ">",
+					Value:  ">",
+					Line:   1,
+					Column: 3,
 				},
 				{
-					Value: "1",
+					Value:  "1",
+					Line:   1,
+					Column: 4,
 				},
 				{
-					Value: "&&",
+					Value:  "&&",
+					Line:   1,
+					Column: 6,
 				},
 				{
-					Value: "member_id",
+					Value:  "member_id",
+					Line:   1,
+					Column: 10,
 				},
 				{
-					Value: ">=",
+					Value:  ">=",
+					Line:   1,
+					Column: 19,
 				},
 				{
-					Value: "2",
+					Value:  "2",
+					Line:   1,
+					Column: 21,
 				},
 				{
-					Value: "&&",
+					Value:  "&&",
+					Line:   1,
+					Column: 23,
 				},
 				{
-					Value: "(",
+					Value:  "(",
+					Line:   1,
+					Column: 26,
 				},
 				{
-					Value: "test_id",
+					Value:  "test_id",
+					Line:   1,
+					Column: 27,
 				},
 				{
-					Value: "<",
+					Value:  "<",
+					Line:   1,
+					Column: 34,
 				},
 				{
-					Value: "10",
+					Value:  "10",
+					Line:   1,
+					Column: 35,
 				},
 				{
-					Value: "||",
+					Value:  "||",
+					Line:   1,
+					Column: 38,
 				},
 				{
-					Value: "pr_id",
+					Value:  "pr_id",
+					Line:   1,
+					Column: 41,
 				},
 				{
-					Value: "<=",
+					Value:  "<=",
+					Line:   1,
+					Column: 46,
 				},
 				{
-					Value: "28",
+					Value:  "28",
+					Line:   1,
+					Column: 48,
 				},
 				{
-					Value: ")",
+					Value:  ")",
+					Line:   1,
+					Column: 50,
 				},
 			},
 		},
@@ -272,55 +815,89 @@ func Test_getToken(t *testing.T) {
 			},
 			want: []*types.TokenAttribute{
 				{
-					Value: "date",
+					Value:  "date",
+					Line:   1,
+					Column: 1,
 				},
 				{
-					Value: ">",
+					Value:  ">",
+					Line:   1,
+					Column: 5,
 				},
 				{
-					Value: "2019-09-01",
+					Value:  "2019-09-01",
+					Line:   1,
+					Column: 6,
 				},
 				{
-					Value: "&&",
+					Value:  "&&",
+					Line:   1,
+					Column: 17,
 				},
 				{
-					Value: "date",
+					Value:  "date",
+					Line:   1,
+					Column: 20,
 				},
 				{
-					Value: "<=",
+					Value:  "<=",
+					Line:   1,
+					Column: 24,
 				},
 				{
-					Value: "2019-10-10",
+					Value:  "2019-10-10",
+					Line:   1,
+					Column: 26,
 				},
 				{
-					Value: "&&",
+					Value:  "&&",
+					Line:   1,
+					Column: 37,
 				},
 				{
-					Value: "(",
+					Value:  "(",
+					Line:   1,
+					Column: 40,
 				},
 				{
-					Value: "segment_id",
+					Value:  "segment_id",
+					Line:   1,
+					Column: 41,
 				},
 				{
-					Value: "=",
+					Value:  "=",
+					Line:   1,
+					Column: 51,
 				},
 				{
-					Value: "12",
+					Value:  "12",
+					Line:   1,
+					Column: 52,
 				},
 				{
-					Value: "||",
+					Value:  "||",
+					Line:   1,
+					Column: 54,
 				},
 				{
-					Value: "segment_id",
+					Value:  "segment_id",
+					Line:   1,
+					Column: 56,
 				},
 				{
-					Value: "=",
+					Value:  "=",
+					Line:   1,
+					Column: 66,
 				},
 				{
-					Value: "13",
+					Value:  "13",
+					Line:   1,
+					Column: 67,
 				},
 				{
-					Value: ")",
+					Value:  ")",
+					Line:   1,
+					Column: 69,
 				},
 			},
 		},
@@ -331,81 +908,1148 @@ func Test_getToken(t *testing.T) {
 			},
 			want: []*types.TokenAttribute{
 				{
-					Value: "date",
+					Value:  "date",
+					Line:   1,
+					Column: 1,
 				},
 				{
-					Value: ">",
+					Value:  ">",
+					Line:   1,
+					Column: 5,
 				},
 				{
 					Value:          "2019-09-01 00:10:00",
 					IsAlphanumeric: true,
+					Line:           1,
+					Column:         6,
 				},
 				{
-					Value: "&&",
+					Value:  "&&",
+					Line:   1,
+					Column: 28,
 				},
 				{
-					Value: "date",
+					Value:  "date",
+					Line:   1,
+					Column: 31,
 				},
 				{
-					Value: "<=",
+					Value:  "<=",
+					Line:   1,
+					Column: 35,
 				},
 				{
-					Value: "2019-10-10",
+					Value:  "2019-10-10",
+					Line:   1,
+					Column: 37,
 				},
 				{
-					Value: "&&",
+					Value:  "&&",
+					Line:   1,
+					Column: 48,
 				},
 				{
-					Value: "(",
+					Value:  "(",
+					Line:   1,
+					Column: 51,
 				},
 				{
-					Value: "segment_id",
+					Value:  "segment_id",
+					Line:   1,
+					Column: 52,
 				},
 				{
-					Value: "=",
+					Value:  "=",
+					Line:   1,
+					Column: 62,
 				},
 				{
 					Value:          "12",
 					IsAlphanumeric: true,
+					Line:           1,
+					Column:         63,
 				},
 				{
-					Value: "||",
+					Value:  "||",
+					Line:   1,
+					Column: 67,
 				},
 				{
-					Value: "segment_id",
+					Value:  "segment_id",
+					Line:   1,
+					Column: 69,
 				},
 				{
-					Value: "=",
+					Value:  "=",
+					Line:   1,
+					Column: 79,
 				},
 				{
-					Value: "13",
+					Value:  "13",
+					Line:   1,
+					Column: 80,
 				},
 				{
-					Value: ")",
+					Value:  ")",
+					Line:   1,
+					Column: 82,
 				},
 			},
 		},
 		{
-			name: "Nil case",
+			name: "Normal case - quoted values",
 			args: args{
-				value: ` `,
+				value: `city='New  York' || name=O'Brien || note="say \"hi\"\\ \d"`,
 			},
-			want: nil,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := getTokenAttributes(tt.args.value); !reflect.DeepEqual(got, tt.want) {
-				strbGot := bytes.Buffer{}
-				for _, g := range got {
-					strbGot.WriteString("\"" + g.Value + "\" ")
-				}
-				strbWant := bytes.Buffer{}
-				for _, g := range tt.want {
-					strbWant.WriteString("\"" + g.Value + "\" ")
-				}
-				t.Errorf("getTokenAttributes() = %v, want %v", strbGot.String(), strbWant.String())
+			want: []*types.TokenAttribute{
+				{
+					Value:  "city",
+					Line:   1,
+					Column: 1,
+				},
+				{
+					Value:  "=",
+					Line:   1,
+					Column: 5,
+				},
+				{
+					Value:          "New  York",
+					IsAlphanumeric: true,
+					Line:           1,
+					Column:         6,
+				},
+				{
+					Value:  "||",
+					Line:   1,
+					Column: 18,
+				},
+				{
+					Value:  "name",
+					Line:   1,
+					Column: 21,
+				},
+				{
+					Value:  "=",
+					Line:   1,
+					Column: 25,
+				},
+				{
+					Value:  "O'Brien",
+					Line:   1,
+					Column: 26,
+				},
+				{
+					Value:  "||",
+					Line:   1,
+					Column: 34,
+				},
+				{
+					Value:  "note",
+					Line:   1,
+					Column: 37,
+				},
+				{
+					Value:  "=",
+					Line:   1,
+					Column: 41,
+				},
+				{
+					Value:          `say "hi"\ \d`,
+					IsAlphanumeric: true,
+					Line:           1,
+					Column:         42,
+				},
+			},
+		},
+		{
+			name: "Normal case - comments",
+			args: args{
+				value: "# leading\nid = A#1 // trailing\n/* block\n comment */ tier /**/ = 'x y' # end",
+			},
+			want: []*types.TokenAttribute{
+				{
+					Value:  "id",
+					Line:   2,
+					Column: 1,
+				},
+				{
+					Value:  "=",
+					Line:   2,
+					Column: 4,
+				},
+				{
+					Value:  "A#1",
+					Line:   2,
+					Column: 6,
+				},
+				{
+					Value:  "tier",
+					Line:   4,
+					Column: 13,
+				},
+				{
+					Value:  "=",
+					Line:   4,
+					Column: 23,
+				},
+				{
+					Value:          "x y",
+					IsAlphanumeric: true,
+					Line:           4,
+					Column:         25,
+				},
+			},
+		},
+		{
+			name: "Normal case - arithmetic without spaces",
+			args: args{
+				value: `a*b%2>=-1 && stock-reserved+1`,
+			},
+			want: []*types.TokenAttribute{
+				{
+					Value:  "a",
+					Line:   1,
+					Column: 1,
+				},
+				{
+					Value:  "*",
+					Line:   1,
+					Column: 2,
+				},
+				{
+					Value:  "b",
+					Line:   1,
+					Column: 3,
+				},
+				{
+					Value:  "%",
+					Line:   1,
+					Column: 4,
+				},
+				{
+					Value:  "2",
+					Line:   1,
+					Column: 5,
+				},
+				{
+					Value:  ">=",
+					Line:   1,
+					Column: 6,
+				},
+				{
+					Value:  "-1",
+					Line:   1,
+					Column: 8,
+				},
+				{
+					Value:  "&&",
+					Line:   1,
+					Column: 11,
+				},
+				{
+					Value:  "stock-reserved",
+					Line:   1,
+					Column: 14,
+				},
+				{
+					Value:  "+",
+					Line:   1,
+					Column: 28,
+				},
+				{
+					Value:  "1",
+					Line:   1,
+					Column: 29,
+				},
+			},
+		},
+		{
+			name: "Nil case",
+			args: args{
+				value: ` `,
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := getTokenAttributes(tt.args.value); !reflect.DeepEqual(got, tt.want) {
+				strbGot := bytes.Buffer{}
+				for _, g := range got {
+					strbGot.WriteString("\"" + g.Value + "\" ")
+				}
+				strbWant := bytes.Buffer{}
+				for _, g := range tt.want {
+					strbWant.WriteString("\"" + g.Value + "\" ")
+				}
+				t.Errorf("getTokenAttributes() = %v, want %v", strbGot.String(), strbWant.String())
+			}
+		})
+	}
+}
+
+func TestBindCondition(t *testing.T) {
+	type args struct {
+		query    string
+		params   map[string]interface{}
+		location *time.Location
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Normal case",
+			args: args{
+				query: `member_id = :member_id && (created_at >= :since || status IN :statuses) && deleted_at != :deleted_at && is_active = :is_active`,
+				params: map[string]interface{}{
+					"member_id":  int64(45),
+					"since":      time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC),
+					"statuses":   []string{"active", "pending"},
+					"deleted_at": nil,
+					"is_active":  true,
+				},
+			},
+			want:    `{"conditions":[{"attribute":{"name":"member_id","operator":"=","value":"45","type":"numeric"}},{"operator":"AND","conditions":[{"attribute":{"name":"created_at","operator":"\u003e=","value":"2020-01-01 10:00:00","type":"date"}},{"operator":"OR","attribute":{"name":"status","operator":"IN","value":"active,pending","type":"alphanumeric"}}]},{"operator":"AND","attribute":{"name":"deleted_at","operator":"!=","value":"null","type":"null"}},{"operator":"AND","attribute":{"name":"is_active","operator":"=","value":"true","type":"boolean"}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Error case - unbound parameter",
+			args: args{
+				query: `member_id = :member_id && created_at >= :since`,
+				params: map[string]interface{}{
+					"member_id": 45,
+				},
+			},
+			want:    `{}`,
+			wantErr: true,
+		},
+		{
+			name: "Error case - list for scalar parameter",
+			args: args{
+				query: `member_id = :member_id`,
+				params: map[string]interface{}{
+					"member_id": []int{45, 46},
+				},
+			},
+			want:    `{}`,
+			wantErr: true,
+		},
+		{
+			name: "Error case - list item with comma",
+			args: args{
+				query: `id IN :ids`,
+				params: map[string]interface{}{
+					"ids": []string{"a,b", "c"},
+				},
+			},
+			want:    `{}`,
+			wantErr: true,
+		},
+		{
+			name: "Normal case - location",
+			args: args{
+				query: `created_at >= :since`,
+				params: map[string]interface{}{
+					"since": time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC),
+				},
+				location: time.FixedZone("Asia/Jakarta", 7*60*60),
+			},
+			want:    `{"conditions":[{"attribute":{"name":"created_at","operator":"\u003e=","value":"2020-01-01 17:00:00","type":"date"}}]}`,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := StructGen{Location: tt.args.location}
+			condition, err := s.GenerateCondition(tt.args.query)
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			got, err := s.BindCondition(condition, tt.args.params)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BindCondition() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			bytes, _ := json.Marshal(got)
+			if string(bytes) != tt.want {
+				t.Errorf("BindCondition() = %s, want %s", bytes, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateConditionRegistry(t *testing.T) {
+	r := registry.New()
+	for _, function := range []registry.Function{
+		{
+			Name:       "is_weekend",
+			Arguments:  []valuetype.ValueType{valuetype.Date},
+			ReturnType: valuetype.Boolean,
+			Call: func(arguments ...interface{}) (interface{}, error) {
+				return nil, nil
+			},
+		},
+		{
+			Name:       "sku_in_category",
+			Arguments:  []valuetype.ValueType{valuetype.Alphanumeric, valuetype.Alphanumeric},
+			ReturnType: valuetype.Boolean,
+			Call: func(arguments ...interface{}) (interface{}, error) {
+				return nil, nil
+			},
+		},
+		{
+			Name:       "discount",
+			Arguments:  []valuetype.ValueType{valuetype.Numeric},
+			Variadic:   true,
+			ReturnType: valuetype.Numeric,
+			Call: func(arguments ...interface{}) (interface{}, error) {
+				return nil, nil
+			},
+		},
+	} {
+		if err := r.RegisterFunction(function); err != nil {
+			t.Fatalf("RegisterFunction() error = %v", err)
+		}
+	}
+	err := r.RegisterOperator(registry.Operator{
+		Name:      "HAS",
+		LeftType:  valuetype.Alphanumeric,
+		RightType: valuetype.Alphanumeric,
+		Call: func(left, right interface{}) (bool, error) {
+			return false, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterOperator() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Normal case - boolean function as condition",
+			query:   `is_weekend(order_date) && !sku_in_category(sku, "shoes")`,
+			want:    `{"conditions":[{"attribute":{"name":"is_weekend(order_date)","operator":"=","value":"true","type":"boolean","left":{"function":"is_weekend","arguments":[{"name":"order_date"}]}}},{"operator":"AND","negate":true,"attribute":{"name":"sku_in_category(sku, \"shoes\")","operator":"=","value":"true","type":"boolean","left":{"function":"sku_in_category","arguments":[{"name":"sku"},{"value":"shoes","type":"alphanumeric"}]}}}]}`,
+			wantErr: false,
+		},
+		{
+			name:    "Normal case - compared function",
+			query:   `IS_WEEKEND(order_date) = false || discount(price, 10, 5) > 100`,
+			want:    `{"conditions":[{"attribute":{"name":"is_weekend(order_date)","operator":"=","value":"false","type":"boolean","left":{"function":"is_weekend","arguments":[{"name":"order_date"}]}}},{"operator":"OR","attribute":{"name":"discount(price, 10, 5)","operator":"\u003e","value":"100","type":"numeric","left":{"function":"discount","arguments":[{"name":"price"},{"value":"10"},{"value":"5"}]}}}]}`,
+			wantErr: false,
+		},
+		{
+			name:    "Normal case - custom operator",
+			query:   `tags HAS "vip" && id = 1`,
+			want:    `{"conditions":[{"attribute":{"name":"tags","operator":"HAS","value":"vip","type":"alphanumeric"}},{"operator":"AND","attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}}]}`,
+			wantErr: false,
+		},
+		{
+			name:    "Error case - wrong number of arguments",
+			query:   `sku_in_category(sku)`,
+			want:    `{}`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - invalid argument type",
+			query:   `is_weekend(5)`,
+			want:    `{}`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - non boolean function as condition",
+			query:   `discount(price)`,
+			want:    `{}`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - reserved function name",
+			query:   `lower(name)`,
+			want:    `{}`,
+			wantErr: true,
+		},
+	}
+	s := StructGen{Registry: r}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GenerateCondition(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GenerateCondition() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			bytes, _ := json.Marshal(got)
+			if string(bytes) != tt.want {
+				t.Errorf("GenerateCondition() = %s, want %s", bytes, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    map[string]string
+		wantErr *SyntaxError
+	}{
+		{
+			name: "Normal case",
+			text: `# customers worth a call
+vip: spend > 1000 && tier = gold
+churned:last_order_at < "2019-09-09" // no order since
+  /* lapsed members
+     are churned too */
+  || status = lapsed
+
+empty_cart: count(items) = 0`,
+			want: map[string]string{
+				"vip":        `{"conditions":[{"attribute":{"name":"spend","operator":"\u003e","value":"1000","type":"numeric"}},{"operator":"AND","attribute":{"name":"tier","operator":"=","value":"gold","type":"alphanumeric"}}]}`,
+				"churned":    `{"conditions":[{"attribute":{"name":"last_order_at","operator":"\u003c","value":"2019-09-09","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"status","operator":"=","value":"lapsed","type":"alphanumeric"}}]}`,
+				"empty_cart": `{"conditions":[{"attribute":{"name":"count(items)","operator":"=","value":"0","type":"numeric","left":{"aggregate":"count","name":"items"}}}]}`,
+			},
+		},
+		{
+			name: "Normal case - empty file",
+			text: "# nothing yet\n",
+			want: map[string]string{},
+		},
+		{
+			name: "Normal case - rule reference",
+			text: "active: status = 1\nvip: @active && spend > 1000",
+			want: map[string]string{
+				"active": `{"conditions":[{"attribute":{"name":"status","operator":"=","value":"1","type":"numeric"}}]}`,
+				"vip":    `{"conditions":[{"conditions":[{"attribute":{"name":"status","operator":"=","value":"1","type":"numeric"}}]},{"operator":"AND","attribute":{"name":"spend","operator":"\u003e","value":"1000","type":"numeric"}}]}`,
+			},
+		},
+		{
+			name:    "Error case - cyclic rule reference",
+			text:    "vip: spend > 1000 && @loyal\nloyal: @vip",
+			wantErr: &SyntaxError{Line: 2, Column: 8, Token: "@vip", Message: "cyclic condition reference @vip -> @loyal -> @vip"},
+		},
+		{
+			name:    "Error case - condition without rule name",
+			text:    "spend > 1000",
+			wantErr: &SyntaxError{Line: 1, Column: 1, Token: "spend", Message: "expected rule name followed by a colon"},
+		},
+		{
+			name:    "Error case - duplicate rule name",
+			text:    "vip: spend > 1000\nvip: tier = gold",
+			wantErr: &SyntaxError{Line: 2, Column: 1, Token: "vip:", Message: "duplicate rule name"},
+		},
+		{
+			name:    "Error case - empty rule",
+			text:    "vip:\nchurned: status = lapsed",
+			wantErr: &SyntaxError{Line: 1, Column: 1, Token: "vip:", Message: "missing condition after rule name"},
+		},
+		{
+			name:    "Error case - invalid condition",
+			text:    "vip: spend > 1000\nchurned: status =",
+			wantErr: &SyntaxError{Line: 2, Column: 17, Token: "=", Message: "missing value after operator"},
+		},
+	}
+	s := StructGen{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GenerateRules(tt.text)
+			if tt.wantErr != nil {
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &syntaxErr) || *syntaxErr != *tt.wantErr {
+					t.Errorf("GenerateRules() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateRules() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("GenerateRules() got %d rules, want %d", len(got), len(tt.want))
+			}
+			for name, want := range tt.want {
+				bytes, _ := json.Marshal(got[name])
+				if string(bytes) != want {
+					t.Errorf("GenerateRules() %s = %s, want %s", name, bytes, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateRulesDialect(t *testing.T) {
+	dialect := DefaultDialect()
+	dialect.Operators[":"] = consts.OperatorEqual
+	s, err := New(dialect)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	got, err := s.GenerateRules("vip: tier : gold\n  && spend > 1000\nchurned:status:lapsed")
+	if err != nil {
+		t.Fatalf("GenerateRules() error = %v", err)
+	}
+	want := map[string]string{
+		"vip":     `{"conditions":[{"attribute":{"name":"tier","operator":"=","value":"gold","type":"alphanumeric"}},{"operator":"AND","attribute":{"name":"spend","operator":"\u003e","value":"1000","type":"numeric"}}]}`,
+		"churned": `{"conditions":[{"attribute":{"name":"status","operator":"=","value":"lapsed","type":"alphanumeric"}}]}`,
+	}
+	if len(got) != len(want) {
+		t.Errorf("GenerateRules() got %d rules, want %d", len(got), len(want))
+	}
+	for name, want := range want {
+		bytes, _ := json.Marshal(got[name])
+		if string(bytes) != want {
+			t.Errorf("GenerateRules() %s = %s, want %s", name, bytes, want)
+		}
+	}
+
+	_, err = s.GenerateRules("vip: tier : gold\nvip: spend > 1000")
+	wantErr := &SyntaxError{Line: 2, Column: 1, Token: "vip", Message: consts.ErrorMessageDuplicateRuleName}
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || *syntaxErr != *wantErr {
+		t.Errorf("GenerateRules() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestGenerateRulesLimits(t *testing.T) {
+	var doubling strings.Builder
+	doubling.WriteString("a0: x = 1\n")
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&doubling, "a%d: @a%d || @a%d\n", i, i-1, i-1)
+	}
+	tests := []struct {
+		name    string
+		limits  types.Limits
+		text    string
+		wantErr *types.LimitError
+	}{
+		{
+			name:   "Normal case - within limits",
+			limits: types.Limits{MaxDepth: 3, MaxTokens: 20},
+			text:   "a: (x = 1)\nb: @a || @a\nc: @b && @a",
+		},
+		{
+			name:    "Error case - exponential expansion",
+			text:    doubling.String(),
+			wantErr: &types.LimitError{Limit: "token count", Max: 4096},
+		},
+		{
+			name:    "Error case - depth of reused expansion",
+			limits:  types.Limits{MaxDepth: 4},
+			text:    "d: ((x = 1))\nr: @d && ((@d))",
+			wantErr: &types.LimitError{Limit: "nesting depth", Max: 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := StructGen{Limits: tt.limits}
+			start := time.Now()
+			_, err := s.GenerateRules(tt.text)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("GenerateRules() took %v, want less than %v", elapsed, time.Second)
+			}
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("GenerateRules() error = %v", err)
+				}
+				return
+			}
+			var limitErr *types.LimitError
+			if !errors.As(err, &limitErr) || *limitErr != *tt.wantErr {
+				t.Errorf("GenerateRules() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateConditionReference(t *testing.T) {
+	r := registry.New()
+	conditions := map[string]string{
+		"tech_divisions": "division=engineering || division=finance",
+		"senior_tech":    "@tech_divisions && level >= 5",
+		"loop_a":         "id = 1 && @loop_b",
+		"loop_b":         "@loop_a",
+		"broken":         "division =",
+	}
+	for name, query := range conditions {
+		if err := r.RegisterCondition(name, query); err != nil {
+			t.Fatalf("RegisterCondition() error = %v", err)
+		}
+	}
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr *SyntaxError
+	}{
+		{
+			name:  "Normal case",
+			query: `@tech_divisions && member_id > 100`,
+			want:  `{"conditions":[{"conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance","type":"alphanumeric"}}]},{"operator":"AND","attribute":{"name":"member_id","operator":"\u003e","value":"100","type":"numeric"}}]}`,
+		},
+		{
+			name:  "Normal case - nested and negated reference",
+			query: `status = 1 || !@senior_tech`,
+			want:  `{"conditions":[{"attribute":{"name":"status","operator":"=","value":"1","type":"numeric"}},{"operator":"OR","negate":true,"conditions":[{"conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance","type":"alphanumeric"}}]},{"operator":"AND","attribute":{"name":"level","operator":"\u003e=","value":"5","type":"numeric"}}]}]}`,
+		},
+		{
+			name:    "Error case - undefined reference",
+			query:   `member_id > 100 && @unknown`,
+			wantErr: &SyntaxError{Line: 1, Column: 20, Token: "@unknown", Message: "undefined condition"},
+		},
+		{
+			name:    "Error case - cyclic reference",
+			query:   `@loop_a`,
+			wantErr: &SyntaxError{Line: 1, Column: 1, Token: "@loop_a", Message: "cyclic condition reference @loop_a -> @loop_b -> @loop_a"},
+		},
+		{
+			name:    "Error case - invalid referenced condition",
+			query:   `@broken`,
+			wantErr: &SyntaxError{Line: 1, Column: 10, Token: "=", Message: "missing value after operator"},
+		},
+	}
+	s := StructGen{Registry: r}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GenerateCondition(tt.query)
+			if tt.wantErr != nil {
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &syntaxErr) || *syntaxErr != *tt.wantErr {
+					t.Errorf("GenerateCondition() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			bytes, _ := json.Marshal(got)
+			if string(bytes) != tt.want {
+				t.Errorf("GenerateCondition() = %s, want %s", bytes, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateConditionLimits(t *testing.T) {
+	r := registry.New()
+	if err := r.RegisterCondition("nested", "((id = 1))"); err != nil {
+		t.Fatalf("RegisterCondition() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		limits  types.Limits
+		query   string
+		wantErr *types.LimitError
+	}{
+		{
+			name:   "Normal case - within limits",
+			limits: types.Limits{MaxDepth: 2, MaxTokens: 20, MaxListSize: 3, MaxQueryLength: 38},
+			query:  `((id IN (1, 2, 3))) && lower(name) = x`,
+		},
+		{
+			name:   "Normal case - disabled limit",
+			limits: types.Limits{MaxDepth: -1},
+			query:  strings.Repeat("(", 100) + "id = 1" + strings.Repeat(")", 100),
+		},
+		{
+			name:    "Error case - default depth",
+			query:   strings.Repeat("(", 100) + "id = 1" + strings.Repeat(")", 100),
+			wantErr: &types.LimitError{Limit: "nesting depth", Max: 32},
+		},
+		{
+			name:    "Error case - depth",
+			limits:  types.Limits{MaxDepth: 2},
+			query:   `id = 1 && (a = 1 || (b = 2 || (c = 3)))`,
+			wantErr: &types.LimitError{Limit: "nesting depth", Max: 2},
+		},
+		{
+			name:    "Error case - depth of nested functions",
+			limits:  types.Limits{MaxDepth: 2},
+			query:   `lower(trim(upper(name))) = x`,
+			wantErr: &types.LimitError{Limit: "nesting depth", Max: 2},
+		},
+		{
+			name:    "Error case - depth of quantifier",
+			limits:  types.Limits{MaxDepth: 1},
+			query:   `any(items, (sku = 1))`,
+			wantErr: &types.LimitError{Limit: "nesting depth", Max: 1},
+		},
+		{
+			name:    "Error case - depth of condition reference",
+			limits:  types.Limits{MaxDepth: 2},
+			query:   `@nested`,
+			wantErr: &types.LimitError{Limit: "nesting depth", Max: 2},
+		},
+		{
+			name:    "Error case - tokens",
+			limits:  types.Limits{MaxTokens: 6},
+			query:   `id = 1 && name = x`,
+			wantErr: &types.LimitError{Limit: "token count", Max: 6},
+		},
+		{
+			name:    "Error case - list size",
+			limits:  types.Limits{MaxListSize: 2},
+			query:   `id IN (1, 2, 3)`,
+			wantErr: &types.LimitError{Limit: "list size", Max: 2},
+		},
+		{
+			name:    "Error case - query length",
+			limits:  types.Limits{MaxQueryLength: 10},
+			query:   `name = "John Doe"`,
+			wantErr: &types.LimitError{Limit: "query length", Max: 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := StructGen{Registry: r, Limits: tt.limits}
+			_, err := s.GenerateCondition(tt.query)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("GenerateCondition() error = %v", err)
+				}
+				return
+			}
+			var limitErr *types.LimitError
+			if !errors.As(err, &limitErr) || *limitErr != *tt.wantErr {
+				t.Errorf("GenerateCondition() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateConditionSymbolRun(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{
+			name:  "Error case - run of less than symbols",
+			query: "b " + strings.Repeat("<", 60000),
+		},
+		{
+			name:  "Error case - run of equal symbols",
+			query: "b " + strings.Repeat("=", 60000) + " 1",
+		},
+	}
+	s := StructGen{Limits: types.Limits{MaxTokens: -1}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			if _, err := s.GenerateCondition(tt.query); err == nil {
+				t.Errorf("GenerateCondition() error = nil, want error")
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("GenerateCondition() took %v, want less than %v", elapsed, time.Second)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	var s StructGen
+	want, err := s.GenerateCondition(`status = paid && (id != 3 || name = "John Doe") && !deleted_at IS NULL && member_id = :member_id`)
+	if err != nil {
+		t.Fatalf("GenerateCondition() error = %v", err)
+	}
+	wantBytes, _ := json.Marshal(want)
+	tests := []struct {
+		name    string
+		dialect func(dialect *Dialect)
+		query   string
+		wantErr *SyntaxError
+	}{
+		{
+			name:    "Normal case - default dialect",
+			dialect: func(dialect *Dialect) {},
+			query:   `status = paid && (id != 3 || name = "John Doe") && !deleted_at IS NULL && member_id = :member_id`,
+		},
+		{
+			name: "Normal case - double equals",
+			dialect: func(dialect *Dialect) {
+				delete(dialect.Operators, "=")
+				delete(dialect.Operators, "!=")
+				dialect.Operators["=="] = consts.OperatorEqual
+				dialect.Operators["<>"] = consts.OperatorNotEqual
+			},
+			query: `status == paid && (id <> 3 || name == "John Doe") && !deleted_at IS NULL && member_id == :member_id`,
+		},
+		{
+			name: "Normal case - colon equality",
+			dialect: func(dialect *Dialect) {
+				dialect.Operators[":"] = consts.OperatorEqual
+			},
+			query: `status:paid and (id != 3 or name: "John Doe") and not deleted_at is null and member_id : :member_id`,
+		},
+		{
+			name: "Normal case - logical symbols and quotes",
+			dialect: func(dialect *Dialect) {
+				dialect.LogicalOperators = map[string]string{"&": consts.LogicalOperatorAnd, "|": consts.LogicalOperatorOr}
+				dialect.Negation = "^"
+				dialect.Quotes = "`"
+			},
+			query: "status = paid & (id != 3 | name = `John Doe`) & ^deleted_at IS NULL & member_id = :member_id",
+		},
+		{
+			name: "Error case - operator of another dialect",
+			dialect: func(dialect *Dialect) {
+				delete(dialect.Operators, "=")
+				dialect.Operators["=="] = consts.OperatorEqual
+			},
+			query:   `status == paid && id = 3`,
+			wantErr: &SyntaxError{Line: 1, Column: 22, Token: "=", Message: "operator = is not part of the dialect, use =="},
+		},
+		{
+			name: "Error case - inequality of another dialect",
+			dialect: func(dialect *Dialect) {
+				delete(dialect.Operators, "!=")
+				dialect.Operators["<>"] = consts.OperatorNotEqual
+			},
+			query:   `status <> paid && id != 3`,
+			wantErr: &SyntaxError{Line: 1, Column: 22, Token: "!", Message: "operator != is not part of the dialect, use <>"},
+		},
+		{
+			name: "Error case - unknown operator",
+			dialect: func(dialect *Dialect) {
+				delete(dialect.Operators, "=")
+				dialect.Operators["=="] = consts.OperatorEqual
+			},
+			query:   `status == paid && id =< 3`,
+			wantErr: &SyntaxError{Line: 1, Column: 22, Token: "=<", Message: consts.ErrorMessageUnknownOperator},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialect := DefaultDialect()
+			tt.dialect(&dialect)
+			s, err := New(dialect)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			got, err := s.GenerateCondition(tt.query)
+			if tt.wantErr != nil {
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &syntaxErr) || *syntaxErr != *tt.wantErr {
+					t.Errorf("GenerateCondition() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			bytes, _ := json.Marshal(got)
+			if string(bytes) != string(wantBytes) {
+				t.Errorf("GenerateCondition() = %s, want %s", bytes, wantBytes)
+			}
+		})
+	}
+}
+
+func TestNewError(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		want    string
+	}{
+		{
+			name:    "Error case - word operator",
+			dialect: Dialect{Operators: map[string]string{"eq": consts.OperatorEqual}},
+			want:    `invalid operator symbol "eq"`,
+		},
+		{
+			name:    "Error case - unknown operator",
+			dialect: Dialect{Operators: map[string]string{"==": "=="}},
+			want:    `symbol "==" maps to unknown operator "=="`,
+		},
+		{
+			name:    "Error case - unknown logical operator",
+			dialect: Dialect{LogicalOperators: map[string]string{"^": "XOR"}},
+			want:    `symbol "^" maps to unknown operator "XOR"`,
+		},
+		{
+			name: "Error case - duplicate symbol",
+			dialect: Dialect{
+				Operators:        map[string]string{"&": consts.OperatorEqual},
+				LogicalOperators: map[string]string{"&": consts.LogicalOperatorAnd},
+			},
+			want: `symbol "&" is already used`,
+		},
+		{
+			name:    "Error case - reserved negation",
+			dialect: Dialect{Negation: "-"},
+			want:    `invalid negation symbol "-"`,
+		},
+		{
+			name:    "Error case - quote used by an operator",
+			dialect: Dialect{Operators: map[string]string{"=": consts.OperatorEqual}, Quotes: "="},
+			want:    `invalid quote symbol "="`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.dialect)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("New() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "Normal case",
+			query: `id=1 && member_id=2 && (division=engineering || division=finance)`,
+			want:  `id = 1 && member_id = 2 && (division = engineering || division = finance)`,
+		},
+		{
+			name:  "Normal case - minimal parentheses",
+			query: `a = 1 || (b = 2 && c = 3) || !(d = 4 && e = 5) || ((f = 6))`,
+			want:  `a = 1 || b = 2 && c = 3 || !(d = 4 && e = 5) || ((f = 6))`,
+		},
+		{
+			name:  "Normal case - quoting",
+			query: `name = "John Doe" && tier = "gold" && note = 'say "hi"' && flag = "true" && path ~ "^\d+\\$"`,
+			want:  `name = "John Doe" && tier = gold && note = "say \"hi\"" && flag = "true" && path ~ "^\\d+\\$"`,
+		},
+		{
+			name:  "Normal case - keyword operators",
+			query: `status IN (1,2) && tier NOT IN ("gold", silver) && price BETWEEN 100 AND 500 && deleted_at IS NULL`,
+			want:  `status IN (1, 2) && tier NOT IN (gold, silver) && price BETWEEN 100 AND 500 && deleted_at IS NULL`,
+		},
+		{
+			name:  "Normal case - expressions and quantifiers",
+			query: `price * qty + 1 >= $budget && FLAG = TRUE || any(items, sku = "A1" && qty > 2)`,
+			want:  `price * qty + 1 >= $budget && FLAG = true || any(items, sku = A1 && qty > 2)`,
+		},
+		{
+			name:  "Nil case",
+			query: ``,
+			want:  ``,
+		},
+	}
+	s := StructGen{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := s.GenerateCondition(tt.query)
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			if got := Format(condition); got != tt.want {
+				t.Errorf("Format() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatCondition(t *testing.T) {
+	name := func(name string) *types.Expression {
+		return &types.Expression{Name: name}
+	}
+	operation := func(operator string, left, right *types.Expression) *types.Expression {
+		return &types.Expression{Operator: operator, Left: left, Right: right}
+	}
+	tests := []struct {
+		name      string
+		attribute types.Attribute
+		want      string
+		// wantAttribute is the parsed attribute, the built one when nil.
+		wantAttribute *types.Attribute
+	}{
+		{
+			name: "Normal case - grouped left operand",
+			attribute: types.Attribute{
+				Name:     "(a + b) * c",
+				Operator: consts.OperatorGreaterThan,
+				Value:    "1",
+				Type:     valuetype.Numeric,
+				Left:     operation("*", operation("+", name("a"), name("b")), name("c")),
+			},
+			want: `(a + b) * c > 1`,
+		},
+		{
+			name: "Normal case - grouped right operands",
+			attribute: types.Attribute{
+				Name:     "x",
+				Operator: consts.OperatorLessThan,
+				Value:    "a - (b - c) / (d * $2)",
+				Right:    operation("-", name("a"), operation("/", operation("-", name("b"), name("c")), operation("*", name("d"), name("2")))),
+			},
+			want: `x < a - (b - c) / (d * $2)`,
+		},
+		{
+			name: "Normal case - hyphenated name",
+			attribute: types.Attribute{
+				Name:     "stock-reserved + 1",
+				Operator: consts.OperatorEqual,
+				Value:    "5",
+				Type:     valuetype.Numeric,
+				Left:     operation("+", name("stock-reserved"), &types.Expression{Value: "1"}),
+			},
+			want: `stock-reserved + 1 = 5`,
+		},
+		{
+			name: "Normal case - numeric list",
+			attribute: types.Attribute{
+				Name:     "status",
+				Operator: consts.OperatorInclude,
+				Value:    "1,.0,00",
+				Type:     valuetype.Numeric,
+			},
+			want: `status IN (1, .0, 00)`,
+		},
+		{
+			name: "Normal case - quoted names",
+			attribute: types.Attribute{
+				Name:     `$"00 % 3`,
+				Operator: consts.OperatorGreaterThan,
+				Value:    "and",
+				Type:     valuetype.Reference,
+				Left:     operation("%", name(`"00`), &types.Expression{Value: "3"}),
+			},
+			want: `$"00 % 3 > $and`,
+		},
+		{
+			name: "Normal case - untyped values",
+			attribute: types.Attribute{
+				Name:     "a",
+				Operator: consts.OperatorEqual,
+				Value:    "1",
+			},
+			want: `a = 1`,
+			wantAttribute: &types.Attribute{
+				Name:     "a",
+				Operator: consts.OperatorEqual,
+				Value:    "1",
+				Type:     valuetype.Numeric,
+			},
+		},
+	}
+	s := StructGen{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attribute := tt.attribute
+			condition := types.Condition{Conditions: []*types.Condition{{Attribute: &attribute}}}
+			got := Format(condition)
+			if got != tt.want {
+				t.Errorf("Format() = %s, want %s", got, tt.want)
+			}
+			parsed, err := s.GenerateCondition(got)
+			if err != nil {
+				t.Fatalf("GenerateCondition(%q) error = %v", got, err)
+			}
+			if tt.wantAttribute != nil {
+				condition.Conditions[0].Attribute = tt.wantAttribute
+			}
+			want, _ := json.Marshal(condition)
+			if parsedJSON, _ := json.Marshal(parsed); string(parsedJSON) != string(want) {
+				t.Errorf("GenerateCondition(%q) = %s, want %s", got, parsedJSON, want)
 			}
 		})
 	}
@@ -0,0 +1,246 @@
+package structgen
+
+import (
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/utils"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var arithmeticRunes = map[rune]bool{
+	'+': true,
+	'-': true,
+	'*': true,
+	'/': true,
+	'%': true,
+}
+
+type tokenizer struct {
+	dialect *Dialect
+	query   []rune
+	index   int
+	line    int
+	column  int
+}
+
+// getTokenAttributes splits query into tokens of the default dialect.
+func getTokenAttributes(query string) ([]*types.TokenAttribute, error) {
+	return defaultDialect.getTokenAttributes(query)
+}
+
+// getTokenAttributes splits query into tokens, skipping whitespace and comments.
+// A comment starts at the beginning of a token, so values like A#1 are kept as
+// they are: # and // comment out the rest of the line, /* */ encloses a block
+// comment.
+func (d *Dialect) getTokenAttributes(query string) ([]*types.TokenAttribute, error) {
+	var tokenAttributes []*types.TokenAttribute
+	t := &tokenizer{dialect: d, query: []rune(query), line: 1, column: 1}
+	for t.index < len(t.query) {
+		char := t.query[t.index]
+		tokenAttribute := &types.TokenAttribute{
+			Line:   t.line,
+			Column: t.column,
+		}
+		switch {
+		case unicode.IsSpace(char):
+			t.next()
+			continue
+		case char == '#', t.hasPrefix("//"):
+			t.skipLine()
+			continue
+		case t.hasPrefix("/*"):
+			if !t.skipBlockComment() {
+				tokenAttribute.Value = "/*"
+				return nil, newSyntaxError(tokenAttribute, consts.ErrorMessageUnterminatedComment)
+			}
+			continue
+		case d.quoteRunes[char]:
+			value, ok := t.readQuoted()
+			if !ok {
+				tokenAttribute.Value = string(char)
+				return nil, newSyntaxError(tokenAttribute, consts.ErrorMessageUnterminatedQuote)
+			}
+			tokenAttribute.Value = value
+			tokenAttribute.IsAlphanumeric = true
+		case char == '(', char == ')', char == ',':
+			tokenAttribute.Value = string(t.next())
+		case t.isParameterStart():
+			t.next()
+			tokenAttribute.Value = consts.ParameterPrefix + t.readWord()
+		case d.symbolRunes[char]:
+			tokenAttribute.Value = t.readSymbol()
+		default:
+			tokenAttribute.Value = t.readWord()
+		}
+		tokenAttributes = append(tokenAttributes, tokenAttribute)
+	}
+	return tokenAttributes, nil
+}
+
+func (t *tokenizer) next() rune {
+	char := t.query[t.index]
+	t.index++
+	if char == '\n' {
+		t.line++
+		t.column = 1
+	} else {
+		t.column++
+	}
+	return char
+}
+
+func (t *tokenizer) hasPrefix(prefix string) bool {
+	end := t.index + len(prefix)
+	return end <= len(t.query) && string(t.query[t.index:end]) == prefix
+}
+
+func (t *tokenizer) skipLine() {
+	for t.index < len(t.query) && t.query[t.index] != '\n' {
+		t.next()
+	}
+}
+
+// skipBlockComment skips a comment closed by */, reporting whether it's closed.
+func (t *tokenizer) skipBlockComment() bool {
+	t.next()
+	t.next()
+	for t.index < len(t.query) {
+		if t.hasPrefix("*/") {
+			t.next()
+			t.next()
+			return true
+		}
+		t.next()
+	}
+	return false
+}
+
+// readQuoted reads a value enclosed in the quote at the current position. A
+// backslash escapes the enclosing quote and itself, any other backslash is kept
+// as is so patterns like \d survive.
+func (t *tokenizer) readQuoted() (string, bool) {
+	quote := t.next()
+	value := make([]rune, 0, 16)
+	for t.index < len(t.query) {
+		char := t.next()
+		switch {
+		case char == quote:
+			return string(value), true
+		case char == '\\' && t.index < len(t.query) && (t.query[t.index] == quote || t.query[t.index] == '\\'):
+			value = append(value, t.next())
+		default:
+			value = append(value, char)
+		}
+	}
+	return "", false
+}
+
+// readSymbol reads the longest known operator at the current position. A run
+// of symbols that doesn't start with a known operator is returned as is, so
+// the parser can report it as unknown. Only the first runes of a run, as many
+// as the longest symbol has, are matched, so a long run is read in linear time.
+func (t *tokenizer) readSymbol() string {
+	end := t.index
+	for end < len(t.query) && end-t.index < t.dialect.symbolLength && t.dialect.symbolRunes[t.query[end]] {
+		end++
+	}
+	length := 0
+	for n := end - t.index; n > 0; n-- {
+		if t.dialect.isSymbolOperator(string(t.query[t.index : t.index+n])) {
+			length = n
+			break
+		}
+	}
+	if length == 0 {
+		for end < len(t.query) && t.dialect.symbolRunes[t.query[end]] {
+			end++
+		}
+		length = end - t.index
+	}
+	start := t.index
+	for i := 0; i < length; i++ {
+		t.next()
+	}
+	return string(t.query[start : start+length])
+}
+
+// readWord reads a word, which ends before an arithmetic operator so that
+// price*quantity is read as three tokens. A - between letters or digits, like
+// in-progress, is part of the word, and so are the operators of a literal like
+// 2019-08-08, -5 or a @condition reference.
+func (t *tokenizer) readWord() string {
+	end := t.index
+	for end < len(t.query) {
+		char := t.query[end]
+		if unicode.IsSpace(char) || t.dialect.symbolRunes[char] || char == '(' || char == ')' || char == ',' {
+			break
+		}
+		end++
+	}
+	word := t.query[t.index:end]
+	if !isLiteralWord(string(word)) {
+		for i, char := range word {
+			if !arithmeticRunes[char] || isInnerHyphen(word, i) {
+				continue
+			}
+			if i == 0 {
+				i = 1
+			}
+			word = word[:i]
+			break
+		}
+	}
+	for range word {
+		t.next()
+	}
+	return string(word)
+}
+
+// isLiteralWord reports whether word is a value whose arithmetic runes are part
+// of it, like a signed number or a date.
+func isLiteralWord(word string) bool {
+	if strings.HasPrefix(word, consts.ConditionPrefix) {
+		return true
+	}
+	if _, err := strconv.ParseFloat(word, 64); err == nil {
+		return !strings.HasPrefix(word, consts.ArithmeticOperatorAdd)
+	}
+	_, err := utils.ParseTime(word)
+	return err == nil
+}
+
+// isInnerHyphen reports whether the rune at index of word is a - between two
+// letters or digits.
+func isInnerHyphen(word []rune, index int) bool {
+	return word[index] == '-' && index > 0 && index+1 < len(word) &&
+		isAlphanumericRune(word[index-1]) && isAlphanumericRune(word[index+1])
+}
+
+func isAlphanumericRune(char rune) bool {
+	return unicode.IsLetter(char) || unicode.IsDigit(char)
+}
+
+// isParameterStart reports whether a parameter like :member_id starts at the
+// current position of a dialect using the parameter prefix in its symbols. It
+// has to start a token and be followed by a name, so that status:paid is still
+// read as a comparison.
+func (t *tokenizer) isParameterStart() bool {
+	prefix := []rune(consts.ParameterPrefix)
+	if !t.dialect.symbolRunes[prefix[0]] || !t.hasPrefix(consts.ParameterPrefix) {
+		return false
+	}
+	if t.index > 0 {
+		previous := t.query[t.index-1]
+		if !unicode.IsSpace(previous) && !t.dialect.symbolRunes[previous] && previous != '(' && previous != ',' {
+			return false
+		}
+	}
+	end := t.index + len(prefix)
+	return end < len(t.query) && isNameStart(t.query[end])
+}
+
+func isNameStart(char rune) bool {
+	return unicode.IsLetter(char) || char == '_'
+}
@@ -0,0 +1,101 @@
+package validator
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"reflect"
+	"strings"
+)
+
+// evaluateAggregate computes an aggregate over the elements of a slice or array
+// attribute. Nil elements and fields are skipped like SQL does, so count only
+// counts the elements having the field, sum of nothing is 0, and avg, min and
+// max of nothing never match. A missing collection is empty.
+func evaluateAggregate(expression *types.Expression, data interface{}) (interface{}, bool, error) {
+	values, err := lookupCollection(expression, data)
+	if err != nil {
+		return nil, false, err
+	}
+	if expression.Aggregate == consts.AggregateCount {
+		return int64(len(values)), true, nil
+	}
+
+	numbers := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		number, ok := toNumber(value)
+		if !ok {
+			return nil, false, fmt.Errorf(consts.ErrorMessageInvalidAggregate, expression.Aggregate, expression.Name, reflect.TypeOf(value))
+		}
+		numbers = append(numbers, number)
+	}
+	if len(numbers) == 0 {
+		return int64(0), expression.Aggregate == consts.AggregateSum, nil
+	}
+
+	result := numbers[0]
+	for _, number := range numbers[1:] {
+		switch expression.Aggregate {
+		case consts.AggregateSum, consts.AggregateAvg:
+			result, _ = calculate(result, consts.ArithmeticOperatorAdd, number)
+		case consts.AggregateMin:
+			if compareNumber(number, consts.OperatorLessThan, result) {
+				result = number
+			}
+		case consts.AggregateMax:
+			if compareNumber(number, consts.OperatorGreaterThan, result) {
+				result = number
+			}
+		}
+	}
+	if expression.Aggregate == consts.AggregateAvg {
+		return toFloat64(result) / float64(len(numbers)), true, nil
+	}
+	return result, true, nil
+}
+
+// lookupCollection returns the non nil values aggregated by expression. The
+// collection is the longest prefix of the name found in data, the rest of the
+// name is the field read from each element, e.g. price for items.price.
+func lookupCollection(expression *types.Expression, data interface{}) ([]interface{}, error) {
+	name, field := expression.Name, ""
+	value, ok := lookupValue("", data, name)
+	for !ok {
+		index := strings.LastIndex(name, ".")
+		if index < 0 {
+			return nil, nil
+		}
+		name, field = expression.Name[:index], expression.Name[index+1:]
+		value, ok = lookupValue("", data, name)
+	}
+
+	rValue := reflect.ValueOf(value)
+	for rValue.Kind() == reflect.Ptr || rValue.Kind() == reflect.Interface {
+		rValue = rValue.Elem()
+	}
+	if !rValue.IsValid() {
+		return nil, nil
+	}
+	if rValue.Kind() != reflect.Slice && rValue.Kind() != reflect.Array {
+		return nil, fmt.Errorf(consts.ErrorMessageInvalidCollection, expression.Aggregate, expression.Name, name, rValue.Type())
+	}
+
+	values := make([]interface{}, 0, rValue.Len())
+	for i := 0; i < rValue.Len(); i++ {
+		if field == "" {
+			if element := rValue.Index(i).Interface(); !isNil(element) {
+				values = append(values, element)
+			}
+			continue
+		}
+		element := toElement(name, rValue.Index(i))
+		if element == nil {
+			continue
+		}
+		fieldValue, _ := lookupValue("", element, field)
+		if !isNil(fieldValue) {
+			values = append(values, fieldValue)
+		}
+	}
+	return values, nil
+}
@@ -1,16 +1,26 @@
 package validator
 
 import (
+	"fmt"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/registry"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/utils"
 	"strings"
 	"time"
 )
 
+// Condition validates objects and conditions against a reference condition.
+// Registry holds the custom functions and operators used by the condition,
+// registry.Default when nil. Now is the clock used by relative dates like
+// now() - 7d, time.Now when nil. Location is the location of dates without a
+// time zone, the default location of utils when nil.
 type Condition struct {
 	*types.Condition
+	Registry *registry.Registry
+	Now      func() time.Time
+	Location *time.Location
 }
 
 func (c *Condition) ValidateCondition(condition types.Condition) (isValid bool, err error) {
@@ -39,24 +49,27 @@ func readAllAttributes(condition *types.Condition, attrMap map[string]bool) {
 
 func (c *Condition) validateConditionAttribute(inputCondition types.Condition) (isValid bool, err error) {
 	if len(c.Conditions) > 0 {
-		for i, subCondition := range c.Conditions {
-			con := Condition{Condition: subCondition}
+		var result logicalResult
+		for _, subCondition := range c.Conditions {
+			con := Condition{Condition: subCondition, Registry: c.Registry, Now: c.Now, Location: c.Location}
 			isSubValid, err := con.validateConditionAttribute(inputCondition)
 			if err != nil {
 				return false, err
 			}
-			if i == 0 {
-				isValid = isSubValid
-			} else {
-				if subCondition.Operator == consts.LogicalOperatorOr {
-					isValid = isValid || isSubValid
-				} else {
-					isValid = isValid && isSubValid
-				}
-			}
+			result.add(subCondition.Operator, isSubValid)
 		}
+		isValid = result.get(false)
 	} else {
-		isValid, _, err = c.validateConditionValue("", inputCondition)
+		con := c
+		if c.Attribute != nil && c.Attribute.Type == valuetype.Reference {
+			if attribute := findAttribute(&inputCondition, c.Attribute.Value); attribute != nil {
+				con = c.resolveReference(attribute.Value, attribute.Type)
+			}
+		}
+		isValid, _, err = con.validateConditionValue("", inputCondition)
+	}
+	if c.Negate {
+		isValid = !isValid
 	}
 	return
 }
@@ -64,7 +77,8 @@ func (c *Condition) validateConditionAttribute(inputCondition types.Condition) (
 func (c *Condition) validateConditionValue(prefix string, condition types.Condition) (isValid, isSkip bool, err error) {
 	isValid = true
 	if len(condition.Conditions) > 0 {
-		for i, subCondition := range condition.Conditions {
+		var result logicalResult
+		for _, subCondition := range condition.Conditions {
 			isSubValid, isSkip, err := c.validateConditionValue(prefix, *subCondition)
 			if err != nil {
 				return false, false, err
@@ -72,33 +86,70 @@ func (c *Condition) validateConditionValue(prefix string, condition types.Condit
 			if isSkip {
 				continue
 			}
-			if i == 0 {
-				isValid = isSubValid
-			} else {
-				if subCondition.Operator == consts.LogicalOperatorOr {
-					isValid = isValid || isSubValid
-				} else {
-					isValid = isValid && isSubValid
-				}
-			}
+			result.add(subCondition.Operator, isSubValid)
 		}
+		isValid = result.get(true)
 	} else {
 		if c.Attribute == nil || condition.Attribute == nil {
 			return false, false, nil
 		}
 		if condition.Attribute.Name == c.Attribute.Name {
-			operator := c.Attribute.Operator
+			switch c.Attribute.Type {
+			case valuetype.Reference:
+				return false, false, nil
+			case valuetype.Parameter:
+				return false, false, fmt.Errorf(consts.ErrorMessageUnboundParameter, c.Attribute.Value)
+			}
+			operator := getComparisonOperator(c.Attribute)
 			switch operator {
 			case consts.OperatorEqual:
 				isValid = strings.EqualFold(condition.Attribute.Value, c.Attribute.Value)
+			case consts.OperatorNotEqual:
+				isValid = !strings.EqualFold(condition.Attribute.Value, c.Attribute.Value)
+			case consts.OperatorLike, consts.OperatorStartsWith, consts.OperatorEndsWith, consts.OperatorContains:
+				isValid = validateString(strings.ToLower(condition.Attribute.Value), operator, strings.ToLower(c.Attribute.Value))
+			case consts.OperatorRegex:
+				isValid, err = validateRegex(condition.Attribute.Value, c.Attribute.Value)
+				if err != nil {
+					return false, false, err
+				}
+			case consts.OperatorIsNull, consts.OperatorIsNotNull:
+				inputOperator := getComparisonOperator(condition.Attribute)
+				isNull := inputOperator == consts.OperatorIsNull ||
+					(inputOperator != consts.OperatorIsNotNull && condition.Attribute.Value == "")
+				isValid = isNull == (operator == consts.OperatorIsNull)
+			case consts.OperatorInclude, consts.OperatorExclude:
+				isValid = false
+				for _, value := range strings.Split(c.Attribute.Value, ",") {
+					if strings.EqualFold(condition.Attribute.Value, strings.TrimSpace(value)) {
+						isValid = true
+						break
+					}
+				}
+				if operator == consts.OperatorExclude {
+					isValid = !isValid
+				}
+			case consts.OperatorBetween:
+				isValid = validateRange(condition.Attribute.Value, c.Attribute.Value, c.Location)
+			case consts.OperatorNotBetween:
+				isValid = !validateRange(condition.Attribute.Value, c.Attribute.Value, c.Location)
 			default:
+				if customOperator, ok := c.getRegistry().Operator(operator); ok {
+					isValid, err = callOperator(customOperator, condition.Attribute.Value, c.Attribute.Value, c.Location)
+					if err != nil {
+						return false, false, err
+					}
+					break
+				}
 				value := condition.Attribute.Value
 				secondValue := c.Attribute.Value
 				valueType := getValueType(c.Attribute.Value)
 
 				switch valueType {
 				case valuetype.Date:
-					isValid = validateTime(utils.StringToTime(value), operator, utils.StringToTime(secondValue))
+					timeValue, _ := toTime(value, c.Location)
+					secondTime, _ := utils.ParseTimeIn(secondValue, c.Location)
+					isValid = validateTime(timeValue, operator, secondTime)
 				default:
 					isValid = validateNumeric(utils.StringToFloat64(value), operator, utils.StringToFloat64(secondValue))
 				}
@@ -107,9 +158,64 @@ func (c *Condition) validateConditionValue(prefix string, condition types.Condit
 			return false, true, nil
 		}
 	}
+	if condition.Negate {
+		isValid = !isValid
+	}
 	return
 }
 
+// resolveReference returns a copy of the condition comparing against value
+// instead of the attribute it references. A condition whose reference can't be
+// resolved keeps the reference type and never matches.
+func (c *Condition) resolveReference(value string, valueType valuetype.ValueType) *Condition {
+	condition := *c.Condition
+	attribute := *c.Attribute
+	attribute.Value, attribute.Type = value, valueType
+	condition.Attribute = &attribute
+	return &Condition{Condition: &condition, Registry: c.Registry, Now: c.Now, Location: c.Location}
+}
+
+// findAttribute returns the first attribute named name in condition.
+func findAttribute(condition *types.Condition, name string) *types.Attribute {
+	if condition.Attribute != nil && condition.Attribute.Name == name {
+		return condition.Attribute
+	}
+	for _, subCondition := range condition.Conditions {
+		if attribute := findAttribute(subCondition, name); attribute != nil {
+			return attribute
+		}
+	}
+	return nil
+}
+
+// logicalResult combines the results of sibling conditions giving AND precedence
+// over OR, the same way SQL evaluates the query generated from the condition.
+type logicalResult struct {
+	isValid    bool
+	isTermTrue bool
+	hasValue   bool
+}
+
+func (r *logicalResult) add(operator string, isValid bool) {
+	switch {
+	case !r.hasValue:
+		r.isTermTrue = isValid
+		r.hasValue = true
+	case operator == consts.LogicalOperatorOr:
+		r.isValid = r.isValid || r.isTermTrue
+		r.isTermTrue = isValid
+	default:
+		r.isTermTrue = r.isTermTrue && isValid
+	}
+}
+
+func (r *logicalResult) get(defaultValue bool) bool {
+	if !r.hasValue {
+		return defaultValue
+	}
+	return r.isValid || r.isTermTrue
+}
+
 func setNonExistAttributeDefaultValue(condition *types.Condition, referenceAttrMap, inputAttrMap map[string]bool) {
 	for attrName, _ := range referenceAttrMap {
 		if _, ok := inputAttrMap[attrName]; !ok {
@@ -151,7 +257,7 @@ func getValueType(value string) valuetype.ValueType {
 		indexVal++
 	}
 	if varType == valuetype.Alphanumeric {
-		if _, err := time.Parse(consts.DateTimeFormat, value); err == nil {
+		if _, err := utils.ParseTime(value); err == nil {
 			varType = valuetype.Date
 		}
 	}
@@ -0,0 +1,320 @@
+package validator
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/utils"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// validateExpression validates a comparison with an arithmetic expression or a
+// function call on either side, resolving the attributes from data. Integers
+// stay integers except for division, any float operand promotes the result to
+// float64, and a duration shifts a time. Arithmetic on a missing or nil
+// attribute and a division by zero never match.
+func (c *Condition) validateExpression(data interface{}) (bool, error) {
+	var left interface{}
+	if c.Attribute.Left != nil {
+		value, ok, err := c.evaluateExpression(c.Attribute.Left, data)
+		if err != nil || !ok {
+			return false, err
+		}
+		left = value
+	} else {
+		left, _ = lookupOperand(data, c.Attribute.Name)
+	}
+
+	var right interface{}
+	switch {
+	case c.Attribute.Right != nil:
+		value, ok, err := c.evaluateExpression(c.Attribute.Right, data)
+		if err != nil || !ok {
+			return false, err
+		}
+		right = value
+	case c.Attribute.Type == valuetype.Parameter:
+		return false, fmt.Errorf(consts.ErrorMessageUnboundParameter, c.Attribute.Value)
+	case c.Attribute.Type == valuetype.Reference:
+		right, _ = lookupValue("", data, c.Attribute.Value)
+	default:
+		if leftNumber, ok := toNumber(left); ok && c.Attribute.Type != valuetype.Alphanumeric {
+			if rightNumber, ok := parseNumber(c.Attribute.Value); ok && isNumericComparison(c.Attribute.Operator) {
+				return compareNumber(leftNumber, c.Attribute.Operator, rightNumber), nil
+			}
+		}
+		return c.validateValue(left)
+	}
+
+	if isNil(right) {
+		return false, nil
+	}
+	if isTime(left) || isTime(right) {
+		leftTime, isLeftTime := toTime(left, c.Location)
+		rightTime, isRightTime := toTime(right, c.Location)
+		if isLeftTime && isRightTime && isNumericComparison(c.Attribute.Operator) {
+			return compareTime(leftTime, c.Attribute.Operator, rightTime), nil
+		}
+	}
+	leftNumber, isLeftNumber := toNumber(left)
+	rightNumber, isRightNumber := toNumber(right)
+	if isLeftNumber && isRightNumber && isNumericComparison(c.Attribute.Operator) {
+		return compareNumber(leftNumber, c.Attribute.Operator, rightNumber), nil
+	}
+	return c.resolveReferenceValue(right).validateValue(left)
+}
+
+// evaluateExpression computes expression on data. Errors only come from
+// registered functions and len of a collection, other failures make the
+// comparison never match.
+func (c *Condition) evaluateExpression(expression *types.Expression, data interface{}) (interface{}, bool, error) {
+	switch {
+	case expression.Aggregate != "":
+		return evaluateAggregate(expression, data)
+	case expression.Function != "":
+		arguments := make([]interface{}, 0, len(expression.Arguments))
+		for _, argument := range expression.Arguments {
+			value, ok, err := c.evaluateExpression(argument, data)
+			if err != nil || !ok {
+				return nil, false, err
+			}
+			arguments = append(arguments, value)
+		}
+		if function, ok := c.getRegistry().Function(expression.Function); ok {
+			return callRegisteredFunction(function, arguments, c.Location)
+		}
+		if value, ok := c.callDateFunction(expression.Function); ok {
+			return value, true, nil
+		}
+		return callFunction(expression.Function, arguments, c.Location)
+	case expression.Operator == "":
+		if expression.Name != "" {
+			value, _ := lookupOperand(data, expression.Name)
+			return value, true, nil
+		}
+		switch expression.Type {
+		case valuetype.Alphanumeric:
+			return expression.Value, true, nil
+		case valuetype.Duration:
+			value, ok := utils.StringToDuration(expression.Value)
+			return value, ok, nil
+		}
+		value, ok := parseNumber(expression.Value)
+		return value, ok, nil
+	}
+	if expression.Left == nil || expression.Right == nil {
+		return nil, false, nil
+	}
+	left, ok, err := c.evaluateExpression(expression.Left, data)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	right, ok, err := c.evaluateExpression(expression.Right, data)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	if duration, ok := right.(time.Duration); ok {
+		value, ok := shiftTime(left, expression.Operator, duration, c.Location)
+		return value, ok, nil
+	}
+	leftNumber, ok := toNumber(left)
+	if !ok {
+		return nil, false, nil
+	}
+	rightNumber, ok := toNumber(right)
+	if !ok {
+		return nil, false, nil
+	}
+	value, ok := calculate(leftNumber, expression.Operator, rightNumber)
+	return value, ok, nil
+}
+
+// lookupOperand returns the value of the attribute name in data, or the
+// difference of its parts for a name like stock-reserved that isn't an
+// attribute of data.
+func lookupOperand(data interface{}, name string) (interface{}, bool) {
+	if value, ok := lookupValue("", data, name); ok {
+		return value, true
+	}
+	return lookupDifference(data, name)
+}
+
+// lookupDifference computes a subtraction written without spaces, e.g.
+// stock-reserved, when name isn't an attribute of data and its parts are
+// numeric attributes or numbers. A known name like user-agent is never split.
+func lookupDifference(data interface{}, name string) (interface{}, bool) {
+	if !strings.Contains(name, consts.ArithmeticOperatorSubtract) {
+		return nil, false
+	}
+	if _, ok := lookupValue("", data, name); ok {
+		return nil, false
+	}
+	var result interface{}
+	for _, part := range strings.Split(name, consts.ArithmeticOperatorSubtract) {
+		value, ok := parseNumber(part)
+		if !ok {
+			if value, ok = lookupValue("", data, part); !ok {
+				return nil, false
+			}
+			if value, ok = toNumber(value); !ok {
+				return nil, false
+			}
+		}
+		if result == nil {
+			result = value
+			continue
+		}
+		if result, ok = calculate(result, consts.ArithmeticOperatorSubtract, value); !ok {
+			return nil, false
+		}
+	}
+	return result, true
+}
+
+func calculate(left interface{}, operator string, right interface{}) (interface{}, bool) {
+	leftInt, isLeftInt := left.(int64)
+	rightInt, isRightInt := right.(int64)
+	if isLeftInt && isRightInt {
+		switch operator {
+		case consts.ArithmeticOperatorAdd:
+			return leftInt + rightInt, true
+		case consts.ArithmeticOperatorSubtract:
+			return leftInt - rightInt, true
+		case consts.ArithmeticOperatorMultiply:
+			return leftInt * rightInt, true
+		case consts.ArithmeticOperatorModulo:
+			if rightInt == 0 {
+				return nil, false
+			}
+			return leftInt % rightInt, true
+		}
+	}
+
+	leftFloat, rightFloat := toFloat64(left), toFloat64(right)
+	switch operator {
+	case consts.ArithmeticOperatorAdd:
+		return leftFloat + rightFloat, true
+	case consts.ArithmeticOperatorSubtract:
+		return leftFloat - rightFloat, true
+	case consts.ArithmeticOperatorMultiply:
+		return leftFloat * rightFloat, true
+	case consts.ArithmeticOperatorDivide:
+		if rightFloat == 0 {
+			return nil, false
+		}
+		return leftFloat / rightFloat, true
+	case consts.ArithmeticOperatorModulo:
+		if rightFloat == 0 {
+			return nil, false
+		}
+		return math.Mod(leftFloat, rightFloat), true
+	}
+	return nil, false
+}
+
+// shiftTime adds or subtracts duration from a time, any other operation or
+// operand never matches.
+func shiftTime(value interface{}, operator string, duration time.Duration, location *time.Location) (interface{}, bool) {
+	timeValue, ok := toTime(value, location)
+	if !ok {
+		return nil, false
+	}
+	switch operator {
+	case consts.ArithmeticOperatorAdd:
+		return timeValue.Add(duration), true
+	case consts.ArithmeticOperatorSubtract:
+		return timeValue.Add(-duration), true
+	}
+	return nil, false
+}
+
+func compareTime(left time.Time, operator string, right time.Time) bool {
+	switch operator {
+	case consts.OperatorEqual:
+		return left.Equal(right)
+	case consts.OperatorNotEqual:
+		return !left.Equal(right)
+	}
+	return validateTime(left, operator, right)
+}
+
+func compareNumber(left interface{}, operator string, right interface{}) bool {
+	leftInt, isLeftInt := left.(int64)
+	rightInt, isRightInt := right.(int64)
+	if isLeftInt && isRightInt {
+		switch operator {
+		case consts.OperatorEqual:
+			return leftInt == rightInt
+		case consts.OperatorNotEqual:
+			return leftInt != rightInt
+		}
+		return validateNumeric(leftInt, operator, rightInt)
+	}
+
+	leftFloat, rightFloat := toFloat64(left), toFloat64(right)
+	switch operator {
+	case consts.OperatorEqual:
+		return leftFloat == rightFloat
+	case consts.OperatorNotEqual:
+		return leftFloat != rightFloat
+	}
+	return validateNumeric(leftFloat, operator, rightFloat)
+}
+
+// toNumber converts a numeric value, or a string holding one, to an int64 or a
+// float64.
+func toNumber(value interface{}) (interface{}, bool) {
+	if isNil(value) {
+		return nil, false
+	}
+	rValue := reflect.ValueOf(value)
+	for rValue.Kind() == reflect.Ptr || rValue.Kind() == reflect.Interface {
+		rValue = rValue.Elem()
+	}
+	switch rValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rValue.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rValue.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rValue.Float(), true
+	case reflect.String:
+		return parseNumber(rValue.String())
+	}
+	return nil, false
+}
+
+func isNumericComparison(operator string) bool {
+	switch operator {
+	case consts.OperatorEqual, consts.OperatorNotEqual,
+		consts.OperatorLessThan, consts.OperatorLessThanEqual,
+		consts.OperatorGreaterThan, consts.OperatorGreaterThanEqual:
+		return true
+	}
+	return false
+}
+
+// parseNumber parses value as an int64, or as a float64 when it isn't an
+// integer.
+func parseNumber(value string) (interface{}, bool) {
+	if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return intValue, true
+	}
+	if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+		return floatValue, true
+	}
+	return nil, false
+}
+
+func toFloat64(value interface{}) float64 {
+	if intValue, ok := value.(int64); ok {
+		return float64(intValue)
+	}
+	floatValue, _ := value.(float64)
+	return floatValue
+}
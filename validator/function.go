@@ -0,0 +1,147 @@
+package validator
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/utils"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// callFunction evaluates a built-in function. Functions return nil for a nil
+// argument, except coalesce which returns its first argument that isn't nil.
+// len only counts the characters of a string, like CHAR_LENGTH in queries, so
+// a collection argument is an error and count should be used instead.
+func callFunction(function string, arguments []interface{}, location *time.Location) (interface{}, bool, error) {
+	if function == consts.FunctionCoalesce {
+		for _, argument := range arguments {
+			if !isNil(argument) {
+				return argument, true, nil
+			}
+		}
+		return nil, true, nil
+	}
+	if len(arguments) != 1 {
+		return nil, false, nil
+	}
+	argument := arguments[0]
+	if isNil(argument) {
+		return nil, true, nil
+	}
+	if function == consts.FunctionLen {
+		switch reflect.Indirect(reflect.ValueOf(argument)).Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return nil, false, fmt.Errorf(consts.ErrorMessageInvalidArgumentType, 1, function, valuetype.Alphanumeric)
+		}
+	}
+	value, ok := callScalarFunction(function, argument, location)
+	return value, ok, nil
+}
+
+// callScalarFunction evaluates a built-in function of a single argument that
+// isn't nil, reading dates without a time zone in location.
+func callScalarFunction(function string, argument interface{}, location *time.Location) (interface{}, bool) {
+
+	switch function {
+	case consts.FunctionLower:
+		return strings.ToLower(toString(argument, location)), true
+	case consts.FunctionUpper:
+		return strings.ToUpper(toString(argument, location)), true
+	case consts.FunctionTrim:
+		return strings.TrimSpace(toString(argument, location)), true
+	case consts.FunctionLen:
+		return int64(utf8.RuneCountInString(toString(argument, location))), true
+	case consts.FunctionAbs:
+		number, ok := toNumber(argument)
+		if !ok {
+			return nil, false
+		}
+		if intValue, ok := number.(int64); ok {
+			if intValue < 0 {
+				return -intValue, true
+			}
+			return intValue, true
+		}
+		return math.Abs(number.(float64)), true
+	case consts.FunctionYear, consts.FunctionMonth, consts.FunctionDay:
+		timeValue, ok := toTime(argument, location)
+		if !ok {
+			return nil, false
+		}
+		switch function {
+		case consts.FunctionYear:
+			return int64(timeValue.Year()), true
+		case consts.FunctionMonth:
+			return int64(timeValue.Month()), true
+		default:
+			return int64(timeValue.Day()), true
+		}
+	}
+	return nil, false
+}
+
+// callDateFunction evaluates the functions returning the current date and time
+// from the clock of the condition.
+func (c *Condition) callDateFunction(function string) (time.Time, bool) {
+	switch function {
+	case consts.FunctionNow:
+		return c.now(), true
+	case consts.FunctionToday:
+		return utils.StartOfDay(c.now()), true
+	case consts.FunctionStartOfMonth:
+		return utils.StartOfMonth(c.now()), true
+	}
+	return time.Time{}, false
+}
+
+func (c *Condition) now() time.Time {
+	if c.Now == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
+
+// isTime reports whether value is a time.Time or a pointer to one.
+func isTime(value interface{}) bool {
+	switch value.(type) {
+	case time.Time, *time.Time:
+		return !isNil(value)
+	}
+	return false
+}
+
+// toTime converts a time, a string in one of the supported date formats or a
+// Unix epoch in seconds or milliseconds to time.Time, in location when the
+// value has no time zone.
+func toTime(value interface{}, location *time.Location) (time.Time, bool) {
+	switch timeValue := value.(type) {
+	case time.Time:
+		return timeValue, true
+	case *time.Time:
+		if timeValue == nil {
+			return time.Time{}, false
+		}
+		return *timeValue, true
+	case string:
+		if parsedTime, err := utils.ParseTimeIn(timeValue, location); err == nil {
+			return parsedTime, true
+		}
+		epoch, err := strconv.ParseInt(timeValue, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return utils.EpochToTimeIn(epoch, location), true
+	case bool, *bool, time.Duration:
+		return time.Time{}, false
+	}
+	number, ok := toNumber(value)
+	if !ok {
+		return time.Time{}, false
+	}
+	return utils.EpochToTimeIn(int64(toFloat64(number)), location), true
+}
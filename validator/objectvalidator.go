@@ -1,17 +1,42 @@
 package validator
 
 import (
+	"container/list"
 	"errors"
 	"fmt"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/types"
 	"github.com/ahmadrezamusthafa/multigenerator/shared/utils"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// regexCacheSize bounds the number of compiled patterns kept by regexCache.
+const regexCacheSize = 256
+
+// regexCache keeps the most recently used compiled patterns, so a pattern
+// isn't compiled again for every validation while patterns read from data
+// can't grow it without bound.
+var regexCache = struct {
+	sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}{
+	entries: make(map[string]*list.Element),
+	order:   list.New(),
+}
+
+// regexEntry is a compiled pattern in regexCache.
+type regexEntry struct {
+	pattern string
+	rgx     *regexp.Regexp
+}
+
 func (c *Condition) Validate(data interface{}) (isValid bool, err error) {
 	if data == nil {
 		return false, fmt.Errorf(consts.ErrorMessageInvalidData, "nil")
@@ -109,8 +134,9 @@ func (c *Condition) prepareDataFromSlice(data interface{}) (interface{}, error)
 
 func (c *Condition) validateAttribute(rType reflect.Type, data interface{}) (isValid, isSkip bool, err error) {
 	if len(c.Conditions) > 0 {
-		for i, subCondition := range c.Conditions {
-			con := Condition{Condition: subCondition}
+		var result logicalResult
+		for _, subCondition := range c.Conditions {
+			con := Condition{Condition: subCondition, Registry: c.Registry, Now: c.Now, Location: c.Location}
 			isSubValid, isSkip, err := con.validateAttribute(rType, data)
 			if err != nil {
 				return false, false, err
@@ -118,28 +144,38 @@ func (c *Condition) validateAttribute(rType reflect.Type, data interface{}) (isV
 			if isSkip {
 				continue
 			}
-			if i == 0 {
-				isValid = isSubValid
-			} else {
-				if subCondition.Operator == consts.LogicalOperatorOr {
-					isValid = isValid || isSubValid
-				} else {
-					isValid = isValid && isSubValid
-				}
-			}
+			result.add(subCondition.Operator, isSubValid)
+		}
+		isValid = result.get(false)
+	} else if c.Quantifier != nil {
+		isValid, err = c.validateQuantifier(data)
+		if err != nil {
+			return false, false, err
+		}
+	} else if c.Attribute != nil && (c.Attribute.Left != nil || c.Attribute.Right != nil || c.isDifference(data)) {
+		isValid, err = c.validateExpression(data)
+		if err != nil {
+			return false, false, err
 		}
 	} else {
+		con := c
+		if c.Attribute != nil && c.Attribute.Type == valuetype.Reference {
+			con = c.resolveObjectReference(data)
+		}
 		switch rType.Kind() {
 		case reflect.Map:
 			if value, ok := data.(map[string]interface{}); ok {
-				isValid, isSkip, err = c.validateMapValue(value)
+				isValid, isSkip, err = con.validateMapValue(value)
 			} else {
 				return false, false, errors.New(consts.ErrorMessageUnableToCastObject)
 			}
 		default:
-			isValid, err = c.validateStructValue("", data)
+			isValid, err = con.validateStructValue("", data)
 		}
 	}
+	if c.Negate && !isSkip {
+		isValid = !isValid
+	}
 	return
 }
 
@@ -159,61 +195,228 @@ func (c *Condition) validateStructValue(prefix string, data interface{}) (isVali
 		tag = prefix + tag
 
 		if tag == c.Attribute.Name {
-			var conditionValue interface{}
-			validationType := valuetype.Numeric
-			value := field.Interface()
-			operator := c.Attribute.Operator
+			return c.validateValue(field.Interface())
+		}
+	}
+	return
+}
 
-			if field.Kind() == reflect.Ptr && field.IsNil() {
-				return false, nil
-			}
+// isDifference reports whether the attribute of the condition is a subtraction
+// written without spaces, see lookupDifference.
+func (c *Condition) isDifference(data interface{}) bool {
+	_, ok := lookupDifference(data, c.Attribute.Name)
+	return ok
+}
 
-			switch value.(type) {
-			case int, int64:
-				value = utils.InterfaceToInt64(value)
-				conditionValue, err = strconv.ParseInt(c.Attribute.Value, 10, 64)
-			case *int, *int64:
-				value = utils.InterfacePtrToInt64(value)
-				conditionValue, err = strconv.ParseInt(c.Attribute.Value, 10, 64)
-			case float32, float64:
-				value = utils.InterfaceToFloat64(value)
-				conditionValue, err = strconv.ParseFloat(c.Attribute.Value, 64)
-			case *float32, *float64:
-				value = utils.InterfacePtrToFloat64(value)
-				conditionValue, err = strconv.ParseInt(c.Attribute.Value, 10, 64)
-			case time.Time:
-				validationType = valuetype.Date
-				conditionValue, err = time.Parse(consts.DateTimeFormat, c.Attribute.Value)
-			case *time.Time:
-				validationType = valuetype.Date
-				res, ok := value.(*time.Time)
-				if ok {
-					value = *res
-				}
-				conditionValue, err = time.Parse(consts.DateTimeFormat, c.Attribute.Value)
-			case bool:
-				validationType = valuetype.Alphanumeric
-				conditionValue = utils.StringToBool(c.Attribute.Value)
-			default:
-				validationType = valuetype.Alphanumeric
-				conditionValue = c.Attribute.Value
+// resolveObjectReference resolves the attribute referenced by the condition
+// from data.
+func (c *Condition) resolveObjectReference(data interface{}) *Condition {
+	value, ok := lookupValue("", data, c.Attribute.Value)
+	if !ok || isNil(value) {
+		return c
+	}
+	return c.resolveReferenceValue(value)
+}
+
+// resolveReferenceValue returns a copy of the condition comparing against the
+// string form of value.
+func (c *Condition) resolveReferenceValue(value interface{}) *Condition {
+	var valueType valuetype.ValueType
+	if reflect.Indirect(reflect.ValueOf(value)).Kind() == reflect.Bool {
+		valueType = valuetype.Boolean
+	}
+	return c.resolveReference(toString(value, c.Location), valueType)
+}
+
+// lookupValue returns the value of the attribute name in data, using the same
+// naming as the validation: json tags for struct fields and key prefixed names
+// for structs inside a map.
+func lookupValue(prefix string, data interface{}, name string) (interface{}, bool) {
+	rValue := reflect.ValueOf(data)
+	switch rValue.Kind() {
+	case reflect.Map:
+		mapValue, ok := data.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok := mapValue[name]; ok {
+			return value, true
+		}
+		for key, value := range mapValue {
+			if len(key) > 0 && !strings.HasPrefix(name, key) {
+				continue
+			}
+			if value, ok := lookupValue(key+".", value, name); ok {
+				return value, true
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < rValue.NumField(); i++ {
+			typeField := rValue.Type().Field(i)
+			tag := typeField.Name
+			jsonTag, ok := typeField.Tag.Lookup("json")
+			if ok && jsonTag != "" {
+				tag = jsonTag
 			}
+			if prefix+tag == name {
+				return rValue.Field(i).Interface(), true
+			}
+		}
+	}
+	return nil, false
+}
+
+func (c *Condition) validateValue(value interface{}) (isValid bool, err error) {
+	switch c.Attribute.Type {
+	case valuetype.Reference:
+		// the referenced attribute is missing or nil, which never matches
+		return false, nil
+	case valuetype.Parameter:
+		return false, fmt.Errorf(consts.ErrorMessageUnboundParameter, c.Attribute.Value)
+	}
+	operator := getComparisonOperator(c.Attribute)
+	switch operator {
+	case consts.OperatorIsNull:
+		return isNil(value), nil
+	case consts.OperatorIsNotNull:
+		return !isNil(value), nil
+	}
+	if isNil(value) {
+		return false, nil
+	}
+	if customOperator, ok := c.getRegistry().Operator(operator); ok {
+		return callOperator(customOperator, value, c.Attribute.Value, c.Location)
+	}
+
+	switch operator {
+	case consts.OperatorInclude, consts.OperatorExclude:
+		for _, conditionValue := range strings.Split(c.Attribute.Value, ",") {
+			isEqual, err := validateEqual(value, strings.TrimSpace(conditionValue), c.Location)
 			if err != nil {
 				return false, err
 			}
-
-			switch operator {
-			case consts.OperatorEqual:
-				isValid = value == conditionValue
-			default:
-				switch validationType {
-				case valuetype.Date:
-					isValid = validateTime(value, operator, conditionValue)
-				default:
-					isValid = validateNumeric(value, operator, conditionValue)
-				}
+			if isEqual {
+				isValid = true
+				break
 			}
 		}
+		if operator == consts.OperatorExclude {
+			isValid = !isValid
+		}
+		return isValid, nil
+	case consts.OperatorBetween:
+		return validateRange(value, c.Attribute.Value, c.Location), nil
+	case consts.OperatorNotBetween:
+		return !validateRange(value, c.Attribute.Value, c.Location), nil
+	case consts.OperatorLike, consts.OperatorStartsWith, consts.OperatorEndsWith, consts.OperatorContains:
+		return validateString(toString(value, c.Location), operator, c.Attribute.Value), nil
+	case consts.OperatorRegex:
+		return validateRegex(toString(value, c.Location), c.Attribute.Value)
+	case consts.OperatorEqual, consts.OperatorNotEqual:
+		var isEqual bool
+		if c.Attribute.Type == valuetype.Boolean {
+			isEqual = validateBoolean(value, c.Attribute.Value, c.Location)
+		} else {
+			isEqual, err = validateEqual(value, c.Attribute.Value, c.Location)
+		}
+		if operator == consts.OperatorNotEqual {
+			isEqual = !isEqual
+		}
+		return isEqual, err
+	}
+
+	if timeValue, conditionTime, ok := castTime(value, c.Attribute.Value, c.Location); ok {
+		return validateTime(timeValue, operator, conditionTime), nil
+	}
+	value, conditionValue, validationType, err := castValue(value, c.Attribute.Value, c.Location)
+	if err != nil {
+		return false, err
+	}
+	switch validationType {
+	case valuetype.Date:
+		isValid = validateTime(value, operator, conditionValue)
+	default:
+		isValid = validateNumeric(value, operator, conditionValue)
+	}
+	return
+}
+
+func validateEqual(value interface{}, rawConditionValue string, location *time.Location) (bool, error) {
+	if timeValue, conditionTime, ok := castTime(value, rawConditionValue, location); ok {
+		return timeValue.Equal(conditionTime), nil
+	}
+	value, conditionValue, validationType, err := castValue(value, rawConditionValue, location)
+	if err != nil {
+		return false, err
+	}
+	if validationType == valuetype.Date {
+		firstTime, _ := value.(time.Time)
+		secondTime, _ := conditionValue.(time.Time)
+		return firstTime.Equal(secondTime), nil
+	}
+	return value == conditionValue, nil
+}
+
+// castTime converts value to a time when rawConditionValue is a date, so date
+// strings and Unix epochs are compared as times.
+func castTime(value interface{}, rawConditionValue string, location *time.Location) (timeValue, conditionTime time.Time, ok bool) {
+	conditionTime, err := utils.ParseTimeIn(rawConditionValue, location)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	timeValue, ok = toTime(value, location)
+	return timeValue, conditionTime, ok
+}
+
+// parseTime parses rawConditionValue as a date in one of the supported formats
+// or as a Unix epoch in seconds or milliseconds.
+func parseTime(rawConditionValue string, location *time.Location) (time.Time, error) {
+	timeValue, err := utils.ParseTimeIn(rawConditionValue, location)
+	if err == nil {
+		return timeValue, nil
+	}
+	if epoch, epochErr := strconv.ParseInt(rawConditionValue, 10, 64); epochErr == nil {
+		return utils.EpochToTimeIn(epoch, location), nil
+	}
+	return time.Time{}, err
+}
+
+// castValue converts value to its comparable form and parses rawConditionValue
+// into the same type.
+func castValue(value interface{}, rawConditionValue string, location *time.Location) (castedValue, conditionValue interface{}, validationType valuetype.ValueType, err error) {
+	validationType = valuetype.Numeric
+	switch value.(type) {
+	case int, int64:
+		castedValue = utils.InterfaceToInt64(value)
+		conditionValue, err = strconv.ParseInt(rawConditionValue, 10, 64)
+	case *int, *int64:
+		castedValue = utils.InterfacePtrToInt64(value)
+		conditionValue, err = strconv.ParseInt(rawConditionValue, 10, 64)
+	case float32, float64:
+		castedValue = utils.InterfaceToFloat64(value)
+		conditionValue, err = strconv.ParseFloat(rawConditionValue, 64)
+	case *float32, *float64:
+		castedValue = utils.InterfacePtrToFloat64(value)
+		conditionValue, err = strconv.ParseFloat(rawConditionValue, 64)
+	case time.Time:
+		validationType = valuetype.Date
+		castedValue = value
+		conditionValue, err = parseTime(rawConditionValue, location)
+	case *time.Time:
+		validationType = valuetype.Date
+		castedValue = value
+		if res, ok := value.(*time.Time); ok {
+			castedValue = *res
+		}
+		conditionValue, err = parseTime(rawConditionValue, location)
+	case bool:
+		validationType = valuetype.Alphanumeric
+		castedValue = value
+		conditionValue = utils.StringToBool(rawConditionValue)
+	default:
+		validationType = valuetype.Alphanumeric
+		castedValue = value
+		conditionValue = rawConditionValue
 	}
 	return
 }
@@ -225,7 +428,11 @@ func (c *Condition) validateMapValue(data map[string]interface{}) (isValid, isSk
 			continue
 		}
 		isSkip = false
-		isValid, err = c.validateStructValue(key+".", value)
+		if key == c.Attribute.Name {
+			isValid, err = c.validateValue(value)
+		} else {
+			isValid, err = c.validateStructValue(key+".", value)
+		}
 		if err != nil {
 			return false, false, err
 		}
@@ -233,9 +440,69 @@ func (c *Condition) validateMapValue(data map[string]interface{}) (isValid, isSk
 			break
 		}
 	}
+	if isSkip {
+		switch getComparisonOperator(c.Attribute) {
+		case consts.OperatorIsNull:
+			return true, false, nil
+		case consts.OperatorIsNotNull:
+			return false, false, nil
+		}
+	}
 	return
 }
 
+// toString returns the string form of value, dereferencing pointers.
+func toString(value interface{}, location *time.Location) string {
+	rValue := reflect.ValueOf(value)
+	for rValue.Kind() == reflect.Ptr || rValue.Kind() == reflect.Interface {
+		rValue = rValue.Elem()
+	}
+	if timeValue, ok := rValue.Interface().(time.Time); ok {
+		return utils.FormatTimeIn(timeValue, location)
+	}
+	return fmt.Sprint(rValue.Interface())
+}
+
+// validateBoolean compares value with a boolean literal. Values that aren't
+// booleans are compared by their string form.
+func validateBoolean(value interface{}, rawConditionValue string, location *time.Location) bool {
+	rValue := reflect.ValueOf(value)
+	for rValue.Kind() == reflect.Ptr || rValue.Kind() == reflect.Interface {
+		rValue = rValue.Elem()
+	}
+	if rValue.Kind() == reflect.Bool {
+		return rValue.Bool() == utils.StringToBool(rawConditionValue)
+	}
+	return strings.EqualFold(toString(value, location), rawConditionValue)
+}
+
+// getComparisonOperator returns the operator of attribute, turning a comparison
+// with the null literal into the equivalent IS NULL or IS NOT NULL.
+func getComparisonOperator(attribute *types.Attribute) string {
+	if attribute.Type == valuetype.Null {
+		switch attribute.Operator {
+		case consts.OperatorEqual:
+			return consts.OperatorIsNull
+		case consts.OperatorNotEqual:
+			return consts.OperatorIsNotNull
+		}
+	}
+	return attribute.Operator
+}
+
+// isNil reports whether value is nil or a nil pointer, interface, map or slice.
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	rValue := reflect.ValueOf(value)
+	switch rValue.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return rValue.IsNil()
+	}
+	return false
+}
+
 func validateTime(firstVal interface{}, operator string, secondVal interface{}) bool {
 	firstTime, ok := firstVal.(time.Time)
 	if !ok {
@@ -258,6 +525,30 @@ func validateTime(firstVal interface{}, operator string, secondVal interface{})
 	}
 }
 
+// validateRange reports whether value lies within the comma separated bounds,
+// inclusively. Date bounds are compared with times, date strings and epochs,
+// numeric bounds with numbers or strings holding them.
+func validateRange(value interface{}, rawConditionValue string, location *time.Location) bool {
+	bounds := strings.Split(rawConditionValue, ",")
+	if len(bounds) != 2 {
+		return false
+	}
+	if low, err := utils.ParseTimeIn(bounds[0], location); err == nil {
+		high, err := utils.ParseTimeIn(bounds[1], location)
+		timeValue, ok := toTime(value, location)
+		return err == nil && ok && !timeValue.Before(low) && !timeValue.After(high)
+	}
+	number, ok := toNumber(value)
+	if !ok {
+		return false
+	}
+	low, isLowValid := parseNumber(bounds[0])
+	high, isHighValid := parseNumber(bounds[1])
+	return isLowValid && isHighValid &&
+		compareNumber(number, consts.OperatorGreaterThanEqual, low) &&
+		compareNumber(number, consts.OperatorLessThanEqual, high)
+}
+
 func validateNumeric(firstVal interface{}, operator string, secondVal interface{}) bool {
 	firstFloat, ok := firstVal.(float64)
 	if !ok {
@@ -287,3 +578,94 @@ func validateNumeric(firstVal interface{}, operator string, secondVal interface{
 		return firstFloat <= secondFloat
 	}
 }
+
+func validateString(value, operator, pattern string) bool {
+	switch operator {
+	case consts.OperatorStartsWith:
+		return strings.HasPrefix(value, pattern)
+	case consts.OperatorEndsWith:
+		return strings.HasSuffix(value, pattern)
+	case consts.OperatorContains:
+		return strings.Contains(value, pattern)
+	default:
+		return matchLike([]rune(value), []rune(pattern))
+	}
+}
+
+func validateRegex(value, pattern string) (bool, error) {
+	rgx, err := compileRegex(pattern)
+	if err != nil {
+		return false, err
+	}
+	return rgx.MatchString(value), nil
+}
+
+// compileRegex compiles pattern and reuses it for later validations while it
+// stays among the regexCacheSize most recently used patterns.
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	regexCache.Lock()
+	if element, ok := regexCache.entries[pattern]; ok {
+		regexCache.order.MoveToFront(element)
+		regexCache.Unlock()
+		return element.Value.(*regexEntry).rgx, nil
+	}
+	regexCache.Unlock()
+
+	rgx, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	regexCache.Lock()
+	defer regexCache.Unlock()
+	if _, ok := regexCache.entries[pattern]; !ok {
+		regexCache.entries[pattern] = regexCache.order.PushFront(&regexEntry{pattern: pattern, rgx: rgx})
+		if regexCache.order.Len() > regexCacheSize {
+			oldest := regexCache.order.Back()
+			regexCache.order.Remove(oldest)
+			delete(regexCache.entries, oldest.Value.(*regexEntry).pattern)
+		}
+	}
+	return rgx, nil
+}
+
+// matchLike matches value against a SQL LIKE pattern, where % matches any
+// sequence of characters, _ matches a single character and \ escapes both.
+func matchLike(value, pattern []rune) bool {
+	valueIndex, patternIndex := 0, 0
+	wildcardIndex, wildcardValueIndex := -1, 0
+	for valueIndex < len(value) {
+		if patternIndex < len(pattern) {
+			switch char := pattern[patternIndex]; char {
+			case '%':
+				wildcardIndex, wildcardValueIndex = patternIndex, valueIndex
+				patternIndex++
+				continue
+			case '_':
+				patternIndex++
+				valueIndex++
+				continue
+			default:
+				length := 1
+				if char == '\\' && patternIndex+1 < len(pattern) {
+					char = pattern[patternIndex+1]
+					length = 2
+				}
+				if char == value[valueIndex] {
+					patternIndex += length
+					valueIndex++
+					continue
+				}
+			}
+		}
+		if wildcardIndex < 0 {
+			return false
+		}
+		wildcardValueIndex++
+		patternIndex, valueIndex = wildcardIndex+1, wildcardValueIndex
+	}
+	for patternIndex < len(pattern) && pattern[patternIndex] == '%' {
+		patternIndex++
+	}
+	return patternIndex == len(pattern)
+}
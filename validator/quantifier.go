@@ -0,0 +1,76 @@
+package validator
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"reflect"
+)
+
+// validateQuantifier validates the quantified condition against the elements of
+// a slice or array attribute. Elements are structs or maps, scalar elements are
+// validated as an attribute named after the slice itself. A missing or nil
+// attribute is an empty slice, where any never matches while all and none do.
+func (c *Condition) validateQuantifier(data interface{}) (isValid bool, err error) {
+	quantifier := c.Quantifier
+	if quantifier.Condition == nil {
+		return false, fmt.Errorf(consts.ErrorMessageInvalidParameter, "quantifier condition")
+	}
+	value, _ := lookupValue("", data, quantifier.Name)
+	rValue := reflect.ValueOf(value)
+	for rValue.Kind() == reflect.Ptr || rValue.Kind() == reflect.Interface {
+		rValue = rValue.Elem()
+	}
+	if !rValue.IsValid() {
+		return quantifier.Operator != consts.QuantifierAny, nil
+	}
+	if rValue.Kind() != reflect.Slice && rValue.Kind() != reflect.Array {
+		return false, fmt.Errorf(consts.ErrorMessageInvalidType, "slice")
+	}
+
+	con := Condition{Condition: quantifier.Condition, Registry: c.Registry, Now: c.Now, Location: c.Location}
+	for i := 0; i < rValue.Len(); i++ {
+		element := toElement(quantifier.Name, rValue.Index(i))
+		if element == nil {
+			continue
+		}
+		isElementValid, _, err := con.validateAttribute(reflect.TypeOf(element), element)
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case quantifier.Operator == consts.QuantifierAny && isElementValid:
+			return true, nil
+		case quantifier.Operator == consts.QuantifierAll && !isElementValid:
+			return false, nil
+		case quantifier.Operator == consts.QuantifierNone && isElementValid:
+			return false, nil
+		}
+	}
+	return quantifier.Operator != consts.QuantifierAny, nil
+}
+
+// toElement returns a slice element in a form validateAttribute accepts, nil
+// for a nil element.
+func toElement(name string, rValue reflect.Value) interface{} {
+	for rValue.Kind() == reflect.Ptr || rValue.Kind() == reflect.Interface {
+		if rValue.IsNil() {
+			return nil
+		}
+		rValue = rValue.Elem()
+	}
+	switch rValue.Kind() {
+	case reflect.Struct:
+		return rValue.Interface()
+	case reflect.Map:
+		if rValue.Type().Key().Kind() != reflect.String {
+			break
+		}
+		element := make(map[string]interface{}, rValue.Len())
+		iterator := rValue.MapRange()
+		for iterator.Next() {
+			element[iterator.Key().String()] = iterator.Value().Interface()
+		}
+		return element
+	}
+	return map[string]interface{}{name: rValue.Interface()}
+}
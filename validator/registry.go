@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/consts"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/enums/valuetype"
+	"github.com/ahmadrezamusthafa/multigenerator/shared/registry"
+	"reflect"
+	"time"
+)
+
+func (c *Condition) getRegistry() *registry.Registry {
+	if c.Registry == nil {
+		return registry.Default
+	}
+	return c.Registry
+}
+
+// callRegisteredFunction converts the arguments to the declared types and calls
+// function. Like the built-in functions it returns nil for a nil argument.
+func callRegisteredFunction(function *registry.Function, arguments []interface{}, location *time.Location) (interface{}, bool, error) {
+	values := make([]interface{}, 0, len(arguments))
+	for index, argument := range arguments {
+		if isNil(argument) {
+			return nil, true, nil
+		}
+		value, ok := convertValue(argument, function.ArgumentType(index), location)
+		if !ok {
+			return nil, false, fmt.Errorf(consts.ErrorMessageInvalidArgument, argument, function.Name)
+		}
+		values = append(values, value)
+	}
+	value, err := function.Call(values...)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// callOperator converts value and the raw condition value to the declared
+// types and calls operator.
+func callOperator(operator *registry.Operator, value interface{}, rawConditionValue string, location *time.Location) (bool, error) {
+	left, ok := convertValue(value, operator.LeftType, location)
+	if !ok {
+		return false, fmt.Errorf(consts.ErrorMessageInvalidArgument, value, operator.Name)
+	}
+	right, ok := convertValue(rawConditionValue, operator.RightType, location)
+	if !ok {
+		return false, fmt.Errorf(consts.ErrorMessageInvalidArgument, rawConditionValue, operator.Name)
+	}
+	return operator.Call(left, right)
+}
+
+// convertValue converts value to a float64 for numeric, a string for
+// alphanumeric, a time.Time for date and a bool for boolean. Other types keep
+// the value as it is.
+func convertValue(value interface{}, valueType valuetype.ValueType, location *time.Location) (interface{}, bool) {
+	switch valueType {
+	case valuetype.Numeric:
+		number, ok := toNumber(value)
+		if !ok {
+			return nil, false
+		}
+		return toFloat64(number), true
+	case valuetype.Alphanumeric:
+		return toString(value, location), true
+	case valuetype.Date:
+		return toTime(value, location)
+	case valuetype.Boolean:
+		rValue := reflect.ValueOf(value)
+		for rValue.Kind() == reflect.Ptr || rValue.Kind() == reflect.Interface {
+			rValue = rValue.Elem()
+		}
+		switch rValue.Kind() {
+		case reflect.Bool:
+			return rValue.Bool(), true
+		case reflect.String:
+			switch rValue.String() {
+			case consts.LiteralTrue:
+				return true, true
+			case consts.LiteralFalse:
+				return false, true
+			}
+		}
+		return nil, false
+	}
+	return value, true
+}